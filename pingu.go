@@ -8,7 +8,6 @@ import (
 )
 
 func main() {
-	logger := logrus.New()
 	config := viper.New()
 
 	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -16,7 +15,14 @@ func main() {
 	config.SetConfigName("pingu")
 	config.AddConfigPath(".")
 
-	if err := config.ReadInConfig(); err == nil {
+	configErr := config.ReadInConfig()
+	logger, err := pingu.NewLogger(config)
+
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	if configErr == nil {
 		logger.WithField("file", config.ConfigFileUsed()).Info("Configuration file loaded")
 	}
 
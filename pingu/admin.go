@@ -0,0 +1,328 @@
+package pingu
+
+import (
+	"fmt"
+	"github.com/hako/durafmt"
+	"github.com/slack-go/slack"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type adminPlugin struct{}
+
+const adminMessage = "Noot! Noot! That command is only available to administrators."
+
+var (
+	aliasRegex   = regexp.MustCompile("^!alias (\\S+) (.+)$")
+	auditRegex   = regexp.MustCompile("^!audit(?: (\\S+))?$")
+	taskRegex    = regexp.MustCompile("^!task (run|pause|resume) (\\S+)$")
+	unaliasRegex = regexp.MustCompile("^!unalias (\\S+)$")
+)
+
+func (pl *adminPlugin) Author() Author {
+	return Author{
+		Email: "jonas@stendahl.me",
+		Name:  "Jonas Stendahl",
+	}
+}
+
+func (pl *adminPlugin) Commands() Commands {
+	return Commands{
+		&Command{
+			Admin:       true,
+			Description: "Adds an alias for a command, as in `!alias lb leaderboard`.",
+			Func:        pl.addAlias,
+			Response:    ResponseEphemeral,
+			Trigger:     aliasRegex,
+		},
+		&Command{
+			Description: "Lists all command aliases.",
+			Func:        pl.listAliases,
+			Response:    ResponseEphemeral,
+			Trigger:     regexp.MustCompile("^!aliases$"),
+		},
+		&Command{
+			Admin:       true,
+			Description: "Removes an alias added with `!alias`.",
+			Func:        pl.removeAlias,
+			Response:    ResponseEphemeral,
+			Trigger:     unaliasRegex,
+		},
+		&Command{
+			Admin:       true,
+			Description: "Lists recent audited commands, optionally filtered by user, channel or command.",
+			Func:        pl.listAudit,
+			Response:    ResponseEphemeral,
+			Trigger:     auditRegex,
+		},
+		&Command{
+			Admin:       true,
+			Description: "Lists all scheduled tasks and when they run.",
+			Func:        pl.listTasks,
+			Response:    ResponseEphemeral,
+			Trigger:     regexp.MustCompile("^!tasks$"),
+		},
+		&Command{
+			Admin:       true,
+			Description: "Runs, pauses or resumes a scheduled task.",
+			Func:        pl.controlTask,
+			Response:    ResponseEphemeral,
+			Trigger:     taskRegex,
+		},
+	}
+}
+
+func (pl *adminPlugin) Name() string {
+	return "Admin"
+}
+
+func (pl *adminPlugin) Tasks() Tasks {
+	return Tasks{}
+}
+
+func (pl *adminPlugin) Version() string {
+	return version
+}
+
+func (pl *adminPlugin) addAlias(pi *Pingu, ev *slack.MessageEvent) error {
+	match := aliasRegex.FindStringSubmatch(ev.Text)
+	alias, expansion := aliasWord(match[1]), aliasWord(match[2])
+
+	if alias == "" || expansion == "" || strings.Contains(alias, ".") {
+		return NewUserError("Noot! Noot! `%s` can't be used as an alias.", match[1])
+	}
+
+	if pi.aliases.reserved(alias) {
+		return NewUserError("Noot! Noot! `!%s` is already a command.", alias)
+	}
+
+	if err := pi.aliases.set(alias, expansion); err != nil {
+		return err
+	}
+
+	_, err := pi.Reply(ev, fmt.Sprintf("Noot! Noot! `!%s` now stands for `!%s`.", alias, expansion))
+
+	return err
+}
+
+func (pl *adminPlugin) controlTask(pi *Pingu, ev *slack.MessageEvent) error {
+	if pi.scheduler == nil {
+		return NewUserError("Noot! Noot! The scheduler isn't running.")
+	}
+
+	match := taskRegex.FindStringSubmatch(ev.Text)
+	j, ok := pi.scheduler.find(match[2])
+
+	if !ok {
+		return NewUserError("Noot! Noot! I couldn't find a task called `%s`.", match[2])
+	}
+
+	var err error
+
+	switch match[1] {
+	case "pause":
+		pi.scheduler.setPaused(j.id, true)
+		_, err = pi.Reply(ev, fmt.Sprintf("Noot! Noot! `%s` has been paused.", j.id))
+	case "resume":
+		pi.scheduler.setPaused(j.id, false)
+		_, err = pi.Reply(ev, fmt.Sprintf("Noot! Noot! `%s` has been resumed.", j.id))
+	case "run":
+		pi.Reply(ev, fmt.Sprintf("Noot! Noot! Running `%s`…", j.id))
+
+		// The task's own failure has already been reported by the scheduler.
+		if taskErr := pi.scheduler.run(j, true); taskErr != nil {
+			_, err = pi.Reply(ev, fmt.Sprintf("Noot! Noot! `%s` failed: %s", j.id, taskErr))
+		} else {
+			_, err = pi.Reply(ev, fmt.Sprintf("Noot! Noot! `%s` finished successfully.", j.id))
+		}
+	}
+
+	return err
+}
+
+func (pl *adminPlugin) listAudit(pi *Pingu, ev *slack.MessageEvent) error {
+	if pi.config.GetString("pingu.data_path") == "" {
+		return NewUserError("Noot! Noot! Auditing requires a data path to be configured.")
+	}
+
+	filter := auditRegex.FindStringSubmatch(ev.Text)[1]
+	entries, err := pi.auditLog.query(func(entry auditEntry) bool {
+		return matchesAudit(entry, filter)
+	}, 20)
+
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		_, err := pi.Reply(ev, "Noot! Noot! There are no matching audit entries.")
+
+		return err
+	}
+
+	lines := make([]string, len(entries))
+
+	for i, entry := range entries {
+		lines[i] = describeAudit(entry)
+	}
+
+	_, err = pi.Respond(ev, RichMessage{Blocks: []Block{
+		Header{Text: "Audit log"},
+		Section{Text: strings.Join(lines, "\n")},
+	}})
+
+	return err
+}
+
+func (pl *adminPlugin) listAliases(pi *Pingu, ev *slack.MessageEvent) error {
+	all := pi.aliases.list()
+
+	if len(all) == 0 {
+		_, err := pi.Reply(ev, "Noot! Noot! There are no aliases.")
+
+		return err
+	}
+
+	lines := make([]string, 0, len(all))
+
+	for _, alias := range sortedAliases(all) {
+		lines = append(lines, fmt.Sprintf("• `!%s` → `!%s`", alias, all[alias]))
+	}
+
+	_, err := pi.Respond(ev, RichMessage{Blocks: []Block{
+		Header{Text: "Aliases"},
+		Section{Text: strings.Join(lines, "\n")},
+	}})
+
+	return err
+}
+
+func (pl *adminPlugin) listTasks(pi *Pingu, ev *slack.MessageEvent) error {
+	if pi.scheduler == nil || len(pi.scheduler.jobs) == 0 {
+		_, err := pi.Reply(ev, "Noot! Noot! There are no scheduled tasks.")
+
+		return err
+	}
+
+	blocks := []Block{Header{Text: "Scheduled tasks"}}
+	lines := make([]string, 0)
+	now := time.Now()
+
+	for i, j := range pi.scheduler.jobs {
+		lines = append(lines, describeTask(j, pi.scheduler.state(j.id), now))
+
+		if i == len(pi.scheduler.jobs)-1 || pi.scheduler.jobs[i+1].plugin != j.plugin {
+			blocks = append(blocks, Section{Text: "*" + j.plugin.Name() + "*\n" + strings.Join(lines, "\n")})
+			lines = lines[:0]
+		}
+	}
+
+	_, err := pi.Respond(ev, RichMessage{Blocks: blocks})
+
+	return err
+}
+
+func (pl *adminPlugin) removeAlias(pi *Pingu, ev *slack.MessageEvent) error {
+	alias := aliasWord(unaliasRegex.FindStringSubmatch(ev.Text)[1])
+	removed, err := pi.aliases.remove(alias)
+
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return NewUserError("Noot! Noot! `!%s` isn't an alias added with `!alias`.", alias)
+	}
+
+	_, err = pi.Reply(ev, fmt.Sprintf("Noot! Noot! `!%s` has been removed.", alias))
+
+	return err
+}
+
+func describeAudit(entry auditEntry) string {
+	line := fmt.Sprintf(
+		"• %s <@%s> ran `%s` in <#%s>: %s",
+		formatTime(entry.Time),
+		entry.User,
+		entry.Arguments,
+		entry.Channel,
+		entry.Outcome,
+	)
+
+	if entry.Outcome != auditDenied {
+		line += " (took " + durafmt.ParseShort(entry.Duration).String() + ")"
+	}
+
+	if entry.Error != "" {
+		line += ", " + entry.Error
+	}
+
+	return line
+}
+
+func describeTask(j *job, state jobState, now time.Time) string {
+	details := []string{"`" + taskSpec(j.task) + "`"}
+
+	if state.Paused {
+		details = append(details, "paused")
+	} else {
+		details = append(details, "next run "+formatTime(j.schedule.Next(now)))
+	}
+
+	if state.LastRun.IsZero() {
+		details = append(details, "never run")
+	} else {
+		details = append(details, fmt.Sprintf(
+			"last run %s (took %s)",
+			formatTime(state.LastRun),
+			durafmt.ParseShort(state.LastDuration),
+		))
+	}
+
+	if state.LastError != "" {
+		details = append(details, "last error: "+state.LastError)
+	}
+
+	return "• `" + j.id + "`: " + strings.Join(details, ", ")
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04 MST")
+}
+
+// matchesAudit matches the entry against a user or channel mention, or else
+// against part of the command.
+func matchesAudit(entry auditEntry, filter string) bool {
+	switch {
+	case filter == "":
+		return true
+	case strings.HasPrefix(filter, "<@"):
+		return mentionID(filter) == entry.User
+	case strings.HasPrefix(filter, "<#"):
+		return mentionID(filter) == entry.Channel
+	default:
+		return strings.Contains(entry.Command, filter) || strings.Contains(entry.Arguments, filter)
+	}
+}
+
+// mentionID returns the ID from a mention such as <@U012345|pingu>.
+func mentionID(mention string) string {
+	return strings.SplitN(strings.Trim(mention, "<@#>"), "|", 2)[0]
+}
+
+func (p *Pingu) isAdmin(user string) bool {
+	for _, admin := range p.config.GetStringSlice("pingu.admins") {
+		if admin == user {
+			return true
+		}
+
+		if strings.HasPrefix(admin, "@") {
+			if u, ok := p.current().directory.UserByName(admin); ok && u.ID == user {
+				return true
+			}
+		}
+	}
+
+	return false
+}
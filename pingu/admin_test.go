@@ -0,0 +1,33 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestIsAdmin(t *testing.T) {
+	config := viper.New()
+	config.Set("pingu.admins", []string{"U012345", "@pingu"})
+
+	directory := newDirectory()
+	directory.update(&slack.TeamJoinEvent{User: slack.User{ID: "U678901", Name: "pingu"}})
+
+	p := &Pingu{state: &state{config: config, workspaces: []*workspace{{directory: directory}}}}
+
+	testCases := []struct {
+		user     string
+		expected bool
+	}{
+		{"U012345", true},
+		{"U678901", true},
+		{"U999999", false},
+		{"", false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := p.isAdmin(testCase.user); testCase.expected != actual {
+			t.Errorf("isAdmin(%q) was incorrect, got: %v, want %v.", testCase.user, actual, testCase.expected)
+		}
+	}
+}
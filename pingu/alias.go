@@ -0,0 +1,319 @@
+package pingu
+
+import (
+	"github.com/spf13/viper"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// aliases maps words that can be used in place of a command onto what they
+// stand for, as in "lb" for "leaderboard". Aliases added at runtime take
+// precedence over configured ones, which take precedence over those declared
+// by plugins. The words of loaded commands can never be aliased, so that an
+// alias can't hijack a command, least of all the ones managing aliases.
+type aliases struct {
+	commands map[string]bool
+	custom   map[string]string
+	defaults map[string]string
+	mu       sync.Mutex
+	storage  *storage
+}
+
+func newAliases(config *viper.Viper, plugins Plugins, storage *storage) *aliases {
+	a := &aliases{
+		commands: map[string]bool{"alias": true, "unalias": true},
+		custom:   make(map[string]string),
+		defaults: make(map[string]string),
+		storage:  storage,
+	}
+
+	for _, plugin := range plugins {
+		for _, command := range plugin.Commands() {
+			words := commandWords(command)
+
+			if len(words) == 0 {
+				continue
+			}
+
+			for _, word := range words {
+				a.commands[word] = true
+			}
+
+			for _, alias := range command.Aliases {
+				a.defaults[aliasWord(alias)] = words[0]
+			}
+		}
+	}
+
+	for alias, expansion := range config.GetStringMapString("pingu.aliases") {
+		a.defaults[aliasWord(alias)] = aliasWord(expansion)
+	}
+
+	return a
+}
+
+// expand replaces the alias that the text starts with, if any, with what it
+// stands for. Aliases are expanded once, so they can't refer to each other.
+// The namespace of a plugin instance is kept, so "!work.lb" becomes
+// "!work.leaderboard".
+func (a *aliases) expand(text string) string {
+	if a == nil || !strings.HasPrefix(text, "!") {
+		return text
+	}
+
+	end := strings.IndexAny(text, " \t\n")
+
+	if end == -1 {
+		end = len(text)
+	}
+
+	word := text[1:end]
+	namespace := ""
+
+	if i := strings.LastIndex(word, "."); i != -1 {
+		namespace, word = word[:i+1], word[i+1:]
+	}
+
+	expansion, ok := a.lookup(word)
+
+	if !ok {
+		return text
+	}
+
+	return "!" + namespace + expansion + text[end:]
+}
+
+// list returns every alias in effect along with what it stands for.
+func (a *aliases) list() map[string]string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	all := make(map[string]string, len(a.defaults)+len(a.custom))
+
+	for alias, expansion := range a.defaults {
+		all[alias] = expansion
+	}
+
+	for alias, expansion := range a.custom {
+		all[alias] = expansion
+	}
+
+	for alias := range all {
+		if a.reserved(alias) {
+			delete(all, alias)
+		}
+	}
+
+	return all
+}
+
+func (a *aliases) lookup(word string) (string, bool) {
+	if a.reserved(word) {
+		return "", false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if expansion, ok := a.custom[word]; ok {
+		return expansion, true
+	}
+
+	expansion, ok := a.defaults[word]
+
+	return expansion, ok
+}
+
+// reload replaces the aliases added at runtime with what was last persisted.
+func (a *aliases) reload() error {
+	custom := make(map[string]string)
+
+	if err := a.storage.load("aliases", &custom); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.custom = custom
+	a.mu.Unlock()
+
+	return nil
+}
+
+// reserved reports whether the word is that of a loaded command.
+func (a *aliases) reserved(word string) bool {
+	return a.commands[word]
+}
+
+// remove removes an alias added at runtime, reporting whether there was one.
+// Aliases that are configured or declared by plugins can't be removed.
+func (a *aliases) remove(alias string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.custom[alias]; !ok {
+		return false, nil
+	}
+
+	delete(a.custom, alias)
+
+	return true, a.storage.save("aliases", a.custom)
+}
+
+func (a *aliases) set(alias string, expansion string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.custom[alias] = expansion
+
+	return a.storage.save("aliases", a.custom)
+}
+
+// aliasWord normalizes an alias or what it stands for, so that both "!lb" and
+// "lb" can be used.
+func aliasWord(s string) string {
+	return strings.TrimPrefix(strings.TrimSpace(s), "!")
+}
+
+// commandWords returns the words that a command can be invoked with, as in
+// "leaderboard" for a trigger matching "!leaderboard 2019", or nothing if its
+// trigger doesn't start with one. The words are taken from the parsed trigger,
+// so that alternations such as "^!(?:jira|issue) " yield every one of them.
+func commandWords(command *Command) []string {
+	re, err := syntax.Parse(command.Trigger.String(), syntax.Perl)
+
+	if err != nil {
+		return nil
+	}
+
+	prefixes, _ := literalPrefixes(re)
+	words := make([]string, 0, len(prefixes))
+	seen := make(map[string]bool, len(prefixes))
+
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(prefix, "!") {
+			continue
+		}
+
+		fields := strings.Fields(prefix[1:])
+
+		if len(fields) == 0 || seen[fields[0]] {
+			continue
+		}
+
+		seen[fields[0]] = true
+		words = append(words, fields[0])
+	}
+
+	return words
+}
+
+// commandWord returns the first word that a command can be invoked with.
+func commandWord(command *Command) string {
+	if words := commandWords(command); len(words) > 0 {
+		return words[0]
+	}
+
+	return ""
+}
+
+// maxLiteralPrefixes caps how many prefixes literalPrefixes expands
+// alternations into, so that a complex trigger can't blow up.
+const maxLiteralPrefixes = 64
+
+// literalPrefixes returns the literal strings that text matched by the regexp
+// can start with, and whether the regexp matches nothing but them. Matching
+// case-insensitively yields the lowercase strings.
+func literalPrefixes(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpEmptyMatch, syntax.OpEndLine, syntax.OpEndText:
+		return []string{""}, true
+	case syntax.OpLiteral:
+		literal := string(re.Rune)
+
+		if re.Flags&syntax.FoldCase != 0 {
+			literal = strings.ToLower(literal)
+		}
+
+		return []string{literal}, true
+	case syntax.OpCapture:
+		return literalPrefixes(re.Sub[0])
+	case syntax.OpCharClass:
+		// Alternations are factored, so "jira|jura" is parsed as "j[iu]ra".
+		all := make([]string, 0)
+
+		for i := 0; i < len(re.Rune); i += 2 {
+			if len(all)+int(re.Rune[i+1]-re.Rune[i]) >= maxLiteralPrefixes {
+				return []string{""}, false
+			}
+
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if re.Flags&syntax.FoldCase != 0 {
+					all = append(all, string(unicode.ToLower(r)))
+				} else {
+					all = append(all, string(r))
+				}
+			}
+		}
+
+		return all, true
+	case syntax.OpQuest:
+		prefixes, _ := literalPrefixes(re.Sub[0])
+
+		return append(prefixes, ""), false
+	case syntax.OpAlternate:
+		all := make([]string, 0)
+		complete := true
+
+		for _, sub := range re.Sub {
+			prefixes, subComplete := literalPrefixes(sub)
+			all = append(all, prefixes...)
+			complete = complete && subComplete
+		}
+
+		return all, complete
+	case syntax.OpConcat:
+		all := []string{""}
+
+		for _, sub := range re.Sub {
+			prefixes, complete := literalPrefixes(sub)
+
+			if len(all)*len(prefixes) > maxLiteralPrefixes {
+				return all, false
+			}
+
+			joined := make([]string, 0, len(all)*len(prefixes))
+
+			for _, before := range all {
+				for _, prefix := range prefixes {
+					joined = append(joined, before+prefix)
+				}
+			}
+
+			all = joined
+
+			if !complete {
+				return all, false
+			}
+		}
+
+		return all, true
+	}
+
+	return []string{""}, false
+}
+
+// sortedAliases returns the aliases in alphabetical order.
+func sortedAliases(all map[string]string) []string {
+	names := make([]string, 0, len(all))
+
+	for alias := range all {
+		names = append(names, alias)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,136 @@
+package pingu
+
+import (
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+)
+
+type aliasPlugin struct {
+	Plugin
+}
+
+func (pl *aliasPlugin) Commands() Commands {
+	return Commands{
+		&Command{Aliases: []string{"lb", "!top"}, Trigger: regexp.MustCompile("^!leaderboard(?: (\\d{4}))?$")},
+		&Command{Aliases: []string{"ignored"}, Trigger: regexp.MustCompile("(?i)jira")},
+		&Command{Trigger: regexp.MustCompile("^!(?:issue|ticket) (\\S+)$")},
+	}
+}
+
+func TestAliasesExpand(t *testing.T) {
+	t.Parallel()
+
+	config := viper.New()
+	config.Set("pingu.aliases", map[string]string{"top": "leaderboard 2020", "!v": "!version"})
+
+	a := newAliases(config, Plugins{&aliasPlugin{}}, newStorage(""))
+	a.set("v", "uptime")
+	a.set("leaderboard", "uptime")
+	a.set("unalias", "ping")
+
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"!lb", "!leaderboard"},
+		{"!lb 2019", "!leaderboard 2019"},
+		{"!top", "!leaderboard 2020"},
+		{"!v", "!uptime"},
+		{"!work.lb", "!work.leaderboard"},
+		{"!ignored", "!ignored"},
+		{"lb", "lb"},
+		{"!lbs", "!lbs"},
+		{"!leaderboard 2019", "!leaderboard 2019"},
+		{"!unalias lb", "!unalias lb"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.text, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := a.expand(testCase.text); actual != testCase.expected {
+				t.Errorf("expand() was incorrect, got: %s, want %s.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestAliasesReserved(t *testing.T) {
+	t.Parallel()
+
+	a := newAliases(viper.New(), Plugins{&aliasPlugin{}, &adminPlugin{}}, newStorage(""))
+	a.set("alias", "ping")
+	a.set("task", "ping")
+
+	testCases := []struct {
+		word     string
+		expected bool
+	}{
+		{"leaderboard", true},
+		{"alias", true},
+		{"unalias", true},
+		{"task", true},
+		{"issue", true},
+		{"ticket", true},
+		{"jira", false},
+		{"lb", false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := a.reserved(testCase.word); actual != testCase.expected {
+			t.Errorf("reserved(%q) was incorrect, got: %v, want %v.", testCase.word, actual, testCase.expected)
+		}
+	}
+
+	if _, ok := a.list()["alias"]; ok {
+		t.Errorf("list() was incorrect, reserved words should not be listed.")
+	}
+
+	if actual := a.expand("!task run aoc/refresh"); actual != "!task run aoc/refresh" {
+		t.Errorf("expand() was incorrect, got: %s, want %s.", actual, "!task run aoc/refresh")
+	}
+}
+
+func TestAliasesPersisted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pingu")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	plugins := Plugins{&aliasPlugin{}}
+	a := newAliases(viper.New(), plugins, newStorage(dir))
+
+	if err := a.set("board", "leaderboard"); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, _ := a.remove("lb"); removed {
+		t.Errorf("remove() was incorrect, aliases declared by plugins can't be removed.")
+	}
+
+	reloaded := newAliases(viper.New(), plugins, newStorage(dir))
+
+	if err := reloaded.reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := reloaded.expand("!board"); actual != "!leaderboard" {
+		t.Errorf("expand() was incorrect, got: %s, want %s.", actual, "!leaderboard")
+	}
+
+	if removed, err := reloaded.remove("board"); !removed || err != nil {
+		t.Errorf("remove() was incorrect, got: %v/%v, want %v/%v.", removed, err, true, nil)
+	}
+
+	if actual := reloaded.expand("!board"); actual != "!board" {
+		t.Errorf("expand() was incorrect, got: %s, want %s.", actual, "!board")
+	}
+}
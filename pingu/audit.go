@@ -0,0 +1,256 @@
+package pingu
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	auditDenied   = "denied"
+	auditFailed   = "failed"
+	auditRejected = "rejected"
+	auditSuccess  = "success"
+)
+
+type auditEntry struct {
+	Arguments string        `json:"arguments"`
+	Channel   string        `json:"channel"`
+	Command   string        `json:"command"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	Outcome   string        `json:"outcome"`
+	Time      time.Time     `json:"time"`
+	User      string        `json:"user"`
+}
+
+// auditLog appends entries to audit.jsonl in the data path. Entries older than
+// the retention are pruned at most once a day, and a retention of zero keeps
+// them forever. If no path is configured nothing is recorded.
+type auditLog struct {
+	mu        sync.Mutex
+	path      string
+	pruned    time.Time
+	retention time.Duration
+}
+
+func newAuditLog(path string, retention time.Duration) *auditLog {
+	return &auditLog{path: path, retention: retention}
+}
+
+func (a *auditLog) append(entry auditEntry) error {
+	if a.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to encode audit entry")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(a.path, 0755); err != nil {
+		return errors.WithMessage(err, "unable to create data path")
+	}
+
+	if a.retention > 0 && entry.Time.Sub(a.pruned) >= 24*time.Hour {
+		if err := a.prune(entry.Time.Add(-a.retention)); err != nil {
+			return err
+		}
+
+		a.pruned = entry.Time
+	}
+
+	f, err := os.OpenFile(a.file(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to open audit log")
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+
+		return errors.WithMessage(err, "unable to write audit entry")
+	}
+
+	return f.Close()
+}
+
+func (a *auditLog) file() string {
+	return filepath.Join(a.path, "audit.jsonl")
+}
+
+// prune rewrites the log without the entries from before cutoff. It must be
+// called with the lock held.
+func (a *auditLog) prune(cutoff time.Time) error {
+	entries, err := a.read()
+
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Time.Before(cutoff) {
+			continue
+		}
+
+		data, err := json.Marshal(entry)
+
+		if err != nil {
+			return errors.WithMessage(err, "unable to encode audit entry")
+		}
+
+		lines = append(lines, string(data)+"\n")
+	}
+
+	if len(lines) == len(entries) {
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile(a.path, "audit.*.tmp")
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to prune audit log")
+	}
+
+	if _, err := tmp.WriteString(strings.Join(lines, "")); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return errors.WithMessage(err, "unable to prune audit log")
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return errors.WithMessage(err, "unable to prune audit log")
+	}
+
+	if err := os.Rename(tmp.Name(), a.file()); err != nil {
+		os.Remove(tmp.Name())
+
+		return errors.WithMessage(err, "unable to prune audit log")
+	}
+
+	return nil
+}
+
+// query returns the last limit entries that match, oldest first.
+func (a *auditLog) query(match func(entry auditEntry) bool, limit int) ([]auditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entries, err := a.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]auditEntry, 0)
+
+	for _, entry := range entries {
+		if match(entry) {
+			matches = append(matches, entry)
+		}
+	}
+
+	if len(matches) > limit {
+		matches = matches[len(matches)-limit:]
+	}
+
+	return matches, nil
+}
+
+func (a *auditLog) read() ([]auditEntry, error) {
+	entries := make([]auditEntry, 0)
+
+	if a.path == "" {
+		return entries, nil
+	}
+
+	f, err := os.Open(a.file())
+
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to read audit log")
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var entry auditEntry
+
+		// A line that was cut short by a crash is skipped rather than making
+		// the whole log unreadable.
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithMessage(err, "unable to read audit log")
+	}
+
+	return entries, nil
+}
+
+// audit records the outcome of an invocation, if the command is an admin
+// command or has asked to be audited.
+func (p *Pingu) audit(inv *invocation, outcome string, err error, duration time.Duration) {
+	if !inv.command.Admin && !inv.command.Audit {
+		return
+	}
+
+	entry := auditEntry{
+		Arguments: inv.event.Text,
+		Channel:   inv.event.Channel,
+		Command:   p.commandKey(inv.plugin, inv.command),
+		Duration:  duration,
+		Outcome:   outcome,
+		Time:      time.Now(),
+		User:      inv.event.User,
+	}
+
+	if err != nil {
+		entry.Error = strings.SplitN(err.Error(), "\n", 2)[0]
+	}
+
+	if err := p.auditLog.append(entry); err != nil {
+		p.logger.Error(err)
+	}
+}
+
+func auditOutcome(err error) string {
+	if err == nil {
+		return auditSuccess
+	}
+
+	if _, ok := asUserError(err); ok {
+		return auditRejected
+	}
+
+	return auditFailed
+}
+
+func (p *Pingu) commandKey(plugin Plugin, command *Command) string {
+	return p.pluginID(plugin) + "/" + command.Trigger.String()
+}
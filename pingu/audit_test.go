@@ -0,0 +1,77 @@
+package pingu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pingu")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	a := newAuditLog(dir, 0)
+	now := time.Date(2020, 12, 1, 5, 0, 0, 0, time.UTC)
+
+	entries := []auditEntry{
+		{Command: "advent-of-code/^!refresh$", Outcome: auditSuccess, Time: now.Add(-48 * time.Hour), User: "U012345"},
+		{Command: "advent-of-code/^!refresh$", Outcome: auditFailed, Time: now.Add(-time.Hour), User: "U012345"},
+		{Command: "admin/^!tasks$", Outcome: auditDenied, Time: now.Add(-time.Minute), User: "U678901"},
+		{Command: "admin/^!tasks$", Outcome: auditSuccess, Time: now, User: "U012345"},
+	}
+
+	for _, entry := range entries {
+		if err := a.append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		filter   string
+		limit    int
+		expected int
+	}{
+		{"", 20, 4},
+		{"", 2, 2},
+		{"<@U012345>", 20, 3},
+		{"<@U678901|pingu>", 20, 1},
+		{"refresh", 20, 2},
+		{"<#C012345>", 20, 0},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := a.query(func(entry auditEntry) bool {
+			return matchesAudit(entry, testCase.filter)
+		}, testCase.limit)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(actual) != testCase.expected {
+			t.Errorf("query(%q, %d) was incorrect, got: %d entries, want %d.", testCase.filter, testCase.limit, len(actual), testCase.expected)
+		}
+	}
+
+	a.retention = 24 * time.Hour
+
+	if err := a.append(auditEntry{Outcome: auditSuccess, Time: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := a.query(func(auditEntry) bool { return true }, 20)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual) != 4 || !actual[0].Time.Equal(entries[1].Time) {
+		t.Errorf("append() didn't prune expired entries, got: %v.", actual)
+	}
+}
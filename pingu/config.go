@@ -0,0 +1,79 @@
+package pingu
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"sort"
+)
+
+// pluginConfig returns the configuration an instance of the plugin gets to see.
+// Every key is read from plugins.<name>.<key>, falling back to the deprecated
+// <name>.<key> for the first instance and then the plugin's default. The
+// shared sub-tree is visible to all plugins as is. Plugins that don't declare
+// their configuration keep getting the global configuration, as they did before.
+func pluginConfig(config *viper.Viper, factory *Factory, name string, logger *logrus.Logger) *viper.Viper {
+	spec := factory.Config
+
+	if spec == nil {
+		logger.WithField("plugin", factory.Name).Warn("Plugin doesn't declare its configuration and receives the global configuration, which is deprecated")
+
+		return config
+	}
+
+	if spec.Global {
+		return config
+	}
+
+	prefix := "plugins." + name + "."
+	legacy := factory.Name + "."
+	trees := []string{"plugins." + name}
+	sub := viper.New()
+	keys := make(map[string]bool)
+
+	if name == factory.Name {
+		trees = append(trees, factory.Name)
+	}
+
+	for key, value := range spec.Defaults {
+		sub.SetDefault(key, value)
+		keys[key] = true
+	}
+
+	for _, tree := range trees {
+		if settings := config.Sub(tree); settings != nil {
+			for _, key := range settings.AllKeys() {
+				keys[key] = true
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(keys))
+
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		switch {
+		case config.IsSet(prefix + key):
+			sub.Set(key, config.Get(prefix+key))
+		case name == factory.Name && config.IsSet(legacy+key):
+			logger.WithFields(logrus.Fields{
+				"key":         legacy + key,
+				"replacement": prefix + key,
+			}).Warn("Deprecated configuration key")
+
+			sub.Set(key, config.Get(legacy+key))
+		}
+	}
+
+	if shared := config.Sub("shared"); shared != nil {
+		for _, key := range shared.AllKeys() {
+			sub.Set("shared."+key, shared.Get(key))
+		}
+	}
+
+	return sub
+}
@@ -0,0 +1,106 @@
+package pingu
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"testing"
+)
+
+func TestPluginConfig(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	config := viper.New()
+	config.Set("plugins.aoc.session", "new")
+	config.Set("plugins.aoc.extra", "extra")
+	config.Set("aoc.session", "old")
+	config.Set("aoc.channel", "#advent-of-code")
+	config.Set("jira.password", "secret")
+	config.Set("shared.timezone", "Europe/Stockholm")
+
+	factory := &Factory{
+		Config: &PluginConfig{Defaults: map[string]interface{}{
+			"channel": "",
+			"session": "",
+			"timeout": 5,
+		}},
+		Name: "aoc",
+	}
+
+	sub := pluginConfig(config, factory, "aoc", logger)
+
+	testCases := []struct {
+		key      string
+		expected interface{}
+	}{
+		{"session", "new"},
+		{"extra", "extra"},
+		{"channel", "#advent-of-code"},
+		{"timeout", 5},
+		{"shared.timezone", "Europe/Stockholm"},
+		{"password", nil},
+		{"jira.password", nil},
+	}
+
+	for _, testCase := range testCases {
+		if actual := sub.Get(testCase.key); actual != testCase.expected {
+			t.Errorf("Get(%q) was incorrect, got: %v, want %v.", testCase.key, actual, testCase.expected)
+		}
+	}
+
+	factory.Config.Global = true
+
+	if pluginConfig(config, factory, "aoc", logger) != config {
+		t.Errorf("pluginConfig() should return the global configuration when opted in.")
+	}
+}
+
+func TestPluginConfigInstance(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	config := viper.New()
+	config.Set("plugins.work.plugin", "aoc")
+	config.Set("plugins.work.session", "work")
+	config.Set("aoc.channel", "#advent-of-code")
+
+	factory := &Factory{
+		Config: &PluginConfig{Defaults: map[string]interface{}{"channel": ""}},
+		Name:   "aoc",
+	}
+
+	sub := pluginConfig(config, factory, "work", logger)
+
+	if actual := sub.GetString("session"); actual != "work" {
+		t.Errorf("GetString(\"session\") was incorrect, got: %s, want %s.", actual, "work")
+	}
+
+	if actual := sub.GetString("channel"); actual != "" {
+		t.Errorf("GetString(\"channel\") was incorrect, got: %s, want %s.", actual, "")
+	}
+}
+
+func TestPluginConfigUndeclared(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	config := viper.New()
+	config.Set("myplugin.token", "secret")
+
+	sub := pluginConfig(config, &Factory{Name: "myplugin"}, "myplugin", logger)
+
+	if sub != config {
+		t.Errorf("pluginConfig() should return the global configuration for plugins that don't declare theirs.")
+	}
+
+	if actual := sub.GetString("myplugin.token"); actual != "secret" {
+		t.Errorf("GetString(\"myplugin.token\") was incorrect, got: %s, want %s.", actual, "secret")
+	}
+}
@@ -0,0 +1,285 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	connectionConnected    = "connected"
+	connectionDisconnected = "disconnected"
+	connectionReconnected  = "reconnected"
+
+	// connectionRetention is how long connection events are kept around.
+	connectionRetention = 30 * 24 * time.Hour
+)
+
+var errConnectionUsed = errors.New("connection has already been used")
+
+// Connectivity summarises the connection history over a period of time.
+type Connectivity struct {
+	Downtime   time.Duration
+	Reconnects int
+}
+
+type backoff struct {
+	factor float64
+	jitter float64
+	max    time.Duration
+	min    time.Duration
+}
+
+// singleUse is the HTTP client of a single connection. The library reconnects
+// on its own as soon as a connection drops, before it can be told to stop, so
+// anything but the first request fails to keep that from bypassing our backoff.
+type singleUse struct {
+	client *http.Client
+	used   int32
+}
+
+// workspaceEvent is an event received from the connection to a workspace.
+type workspaceEvent struct {
+	msg       slack.RTMEvent
+	workspace *workspace
+}
+
+type connectionEvent struct {
+	Reason string    `json:"reason,omitempty"`
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+}
+
+type connections struct {
+	events  []connectionEvent
+	mu      sync.Mutex
+	name    string
+	storage *storage
+}
+
+func newBackoff(min time.Duration, max time.Duration, factor float64, jitter float64) *backoff {
+	if min <= 0 {
+		min = time.Second
+	}
+
+	if max < min {
+		max = min
+	}
+
+	if factor < 1 {
+		factor = 1
+	}
+
+	return &backoff{
+		factor: factor,
+		jitter: math.Max(0, math.Min(jitter, 1)),
+		max:    max,
+		min:    min,
+	}
+}
+
+// duration returns how long to wait before the given attempt, counting from
+// zero. The jitter spreads the wait by up to that fraction in either direction.
+func (b *backoff) duration(attempt int) time.Duration {
+	d := math.Min(float64(b.min)*math.Pow(b.factor, float64(attempt)), float64(b.max))
+
+	if b.jitter > 0 {
+		d += d * b.jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(d)
+}
+
+func newConnections(storage *storage, name string) *connections {
+	c := &connections{
+		events:  make([]connectionEvent, 0),
+		name:    name,
+		storage: storage,
+	}
+
+	if err := storage.load(name, &c.events); err != nil {
+		c.events = make([]connectionEvent, 0)
+	}
+
+	return c
+}
+
+func (c *connections) connected(now time.Time, initial bool) error {
+	typ := connectionReconnected
+
+	if initial {
+		typ = connectionConnected
+	}
+
+	return c.record(connectionEvent{Time: now, Type: typ})
+}
+
+func (c *connections) disconnected(now time.Time, reason string) error {
+	return c.record(connectionEvent{Reason: reason, Time: now, Type: connectionDisconnected})
+}
+
+func (c *connections) record(event connectionEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.events = append(c.events, event)
+
+	for len(c.events) > 0 && event.Time.Sub(c.events[0].Time) > connectionRetention {
+		c.events = c.events[1:]
+	}
+
+	return c.storage.save(c.name, c.events)
+}
+
+// summary counts the reconnects since the given time, and how long the
+// connection was down for, including while the bot itself wasn't running.
+func (c *connections) summary(since time.Time, now time.Time) Connectivity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		connectivity Connectivity
+		down         time.Time
+	)
+
+	for _, event := range c.events {
+		switch event.Type {
+		case connectionDisconnected:
+			if down.IsZero() {
+				down = event.Time
+			}
+		case connectionConnected, connectionReconnected:
+			if event.Type == connectionReconnected && !event.Time.Before(since) {
+				connectivity.Reconnects++
+			}
+
+			if !down.IsZero() {
+				connectivity.Downtime += overlap(down, event.Time, since)
+				down = time.Time{}
+			}
+		}
+	}
+
+	if !down.IsZero() {
+		connectivity.Downtime += overlap(down, now, since)
+	}
+
+	return connectivity
+}
+
+func overlap(from time.Time, to time.Time, since time.Time) time.Duration {
+	if from.Before(since) {
+		from = since
+	}
+
+	if to.Before(from) {
+		return 0
+	}
+
+	return to.Sub(from)
+}
+
+func (p *Pingu) Connectivity(since time.Time) Connectivity {
+	return p.current().connections.summary(since, time.Now())
+}
+
+func (s *singleUse) Do(req *http.Request) (*http.Response, error) {
+	if !atomic.CompareAndSwapInt32(&s.used, 0, 1) {
+		return nil, errConnectionUsed
+	}
+
+	return s.client.Do(req)
+}
+
+// connect keeps a connection to the workspace open until stopped, passing its
+// events on. Every connection gets a new RTM that is only able to connect once,
+// so that the wait between attempts is decided by our own backoff rather than
+// the library's.
+func (p *Pingu) connect(ws *workspace, events chan<- workspaceEvent, stop <-chan struct{}) {
+	attempt := 0
+
+	for {
+		rtm := slack.New(ws.token, slack.OptionHTTPClient(&singleUse{client: &http.Client{}})).NewRTM()
+
+		go rtm.ManageConnection()
+
+		if p.forward(ws, rtm, events, stop) {
+			attempt = 0
+		}
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		wait := p.backoff.duration(attempt)
+		attempt++
+
+		p.logger.WithFields(logrus.Fields{
+			"attempt":   attempt,
+			"wait":      wait,
+			"workspace": ws.name,
+		}).Info("Reconnecting")
+
+		select {
+		case <-time.After(wait):
+		case <-stop:
+			return
+		}
+	}
+}
+
+// forward passes events from rtm on until its connection fails or is stopped,
+// and reports whether it ever managed to connect.
+func (p *Pingu) forward(ws *workspace, rtm *slack.RTM, events chan<- workspaceEvent, stop <-chan struct{}) bool {
+	connected := false
+
+	for {
+		select {
+		case msg := <-rtm.IncomingEvents:
+			switch ev := msg.Data.(type) {
+			case *slack.ConnectedEvent:
+				connected = true
+			case *slack.ConnectionErrorEvent:
+				p.logger.WithFields(logrus.Fields{
+					"error":     ev.ErrorObj,
+					"workspace": ws.name,
+				}).Warn("Connection failed")
+				go rtm.Disconnect()
+
+				return connected
+			case *slack.DisconnectedEvent:
+				// The library's own attempt to reconnect fails, as the RTM can
+				// only connect once, so it's told to give up and a new one is
+				// made after a backoff.
+				go rtm.Disconnect()
+
+				select {
+				case events <- workspaceEvent{msg: msg, workspace: ws}:
+				case <-stop:
+				}
+
+				return connected
+			}
+
+			select {
+			case events <- workspaceEvent{msg: msg, workspace: ws}:
+			case <-stop:
+				rtm.Disconnect()
+
+				return connected
+			}
+		case <-stop:
+			rtm.Disconnect()
+
+			return connected
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package pingu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	b := newBackoff(time.Second, time.Minute, 2, 0)
+
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{6, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, testCase := range testCases {
+		if actual := b.duration(testCase.attempt); actual != testCase.expected {
+			t.Errorf("duration(%d) was incorrect, got: %s, want %s.", testCase.attempt, actual, testCase.expected)
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	t.Parallel()
+
+	b := newBackoff(10*time.Second, time.Minute, 2, 0.5)
+
+	for i := 0; i < 100; i++ {
+		if actual := b.duration(0); actual < 5*time.Second || actual > 15*time.Second {
+			t.Fatalf("duration(0) was outside the jitter, got: %s.", actual)
+		}
+	}
+}
+
+func TestConnectionsSummary(t *testing.T) {
+	t.Parallel()
+
+	c := newConnections(newStorage(""), "connections")
+	now := time.Date(2020, 12, 8, 12, 0, 0, 0, time.UTC)
+
+	c.connected(now.Add(-10*24*time.Hour), true)
+	c.disconnected(now.Add(-3*24*time.Hour), "deadman")
+	c.connected(now.Add(-3*24*time.Hour+time.Minute), false)
+	c.disconnected(now.Add(-25*time.Hour), "shutdown")
+	c.connected(now.Add(-23*time.Hour), true)
+	c.disconnected(now.Add(-time.Hour), "goodbye")
+	c.connected(now.Add(-time.Hour+30*time.Second), false)
+
+	testCases := []struct {
+		since    time.Duration
+		expected Connectivity
+	}{
+		{24 * time.Hour, Connectivity{Downtime: time.Hour + 30*time.Second, Reconnects: 1}},
+		{7 * 24 * time.Hour, Connectivity{Downtime: 2*time.Hour + time.Minute + 30*time.Second, Reconnects: 2}},
+		{time.Minute, Connectivity{}},
+	}
+
+	for _, testCase := range testCases {
+		if actual := c.summary(now.Add(-testCase.since), now); actual != testCase.expected {
+			t.Errorf("summary(%s) was incorrect, got: %+v, want %+v.", testCase.since, actual, testCase.expected)
+		}
+	}
+}
+
+func TestSingleUse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &singleUse{client: server.Client()}
+
+	for i, expected := range []error{nil, errConnectionUsed, errConnectionUsed} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		res, err := client.Do(req)
+
+		if res != nil {
+			res.Body.Close()
+		}
+
+		if err != expected {
+			t.Errorf("Do() #%d was incorrect, got: %v, want %v.", i, err, expected)
+		}
+	}
+}
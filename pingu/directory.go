@@ -0,0 +1,230 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"strings"
+	"sync"
+)
+
+type Directory struct {
+	channels   map[string]slack.Channel
+	mu         sync.RWMutex
+	usergroups map[string]slack.UserGroup
+	users      map[string]slack.User
+}
+
+func newDirectory() *Directory {
+	return &Directory{
+		channels:   make(map[string]slack.Channel),
+		usergroups: make(map[string]slack.UserGroup),
+		users:      make(map[string]slack.User),
+	}
+}
+
+func (d *Directory) Channel(id string) (slack.Channel, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	channel, ok := d.channels[id]
+
+	return channel, ok
+}
+
+func (d *Directory) ChannelByName(name string) (slack.Channel, bool) {
+	name = strings.TrimPrefix(name, "#")
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, channel := range d.channels {
+		if strings.EqualFold(channel.Name, name) {
+			return channel, true
+		}
+	}
+
+	return slack.Channel{}, false
+}
+
+// ChannelID resolves a "#name" reference to the ID of the channel. Anything
+// else, including unknown names, is returned as is.
+func (d *Directory) ChannelID(channel string) string {
+	if !strings.HasPrefix(channel, "#") {
+		return channel
+	}
+
+	if c, ok := d.ChannelByName(channel); ok {
+		return c.ID
+	}
+
+	return channel
+}
+
+func (d *Directory) User(id string) (slack.User, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	user, ok := d.users[id]
+
+	return user, ok
+}
+
+func (d *Directory) UserByEmail(email string) (slack.User, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, user := range d.users {
+		if user.Profile.Email != "" && strings.EqualFold(user.Profile.Email, email) {
+			return user, true
+		}
+	}
+
+	return slack.User{}, false
+}
+
+func (d *Directory) UserByName(name string) (slack.User, bool) {
+	name = strings.TrimPrefix(name, "@")
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, user := range d.users {
+		if strings.EqualFold(user.Name, name) || strings.EqualFold(user.Profile.DisplayName, name) {
+			return user, true
+		}
+	}
+
+	for _, user := range d.users {
+		if strings.EqualFold(user.RealName, name) {
+			return user, true
+		}
+	}
+
+	return slack.User{}, false
+}
+
+func (d *Directory) UserGroup(id string) (slack.UserGroup, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	usergroup, ok := d.usergroups[id]
+
+	return usergroup, ok
+}
+
+func (d *Directory) UserGroupByHandle(handle string) (slack.UserGroup, bool) {
+	handle = strings.TrimPrefix(handle, "@")
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, usergroup := range d.usergroups {
+		if strings.EqualFold(usergroup.Handle, handle) || strings.EqualFold(usergroup.Name, handle) {
+			return usergroup, true
+		}
+	}
+
+	return slack.UserGroup{}, false
+}
+
+func (d *Directory) refresh(client *slack.Client) error {
+	users, err := client.GetUsers()
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to retrieve users")
+	}
+
+	channels := make([]slack.Channel, 0)
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: "true",
+		Limit:           1000,
+		Types:           []string{"public_channel", "private_channel"},
+	}
+
+	for {
+		page, cursor, err := client.GetConversations(params)
+
+		if err != nil {
+			return errors.WithMessage(err, "unable to retrieve channels")
+		}
+
+		channels = append(channels, page...)
+
+		if cursor == "" {
+			break
+		}
+
+		params.Cursor = cursor
+	}
+
+	// User groups require an extra scope and are not available on free
+	// workspaces, so a failure here shouldn't prevent the rest of the refresh.
+	usergroups, usergroupsErr := client.GetUserGroups(slack.GetUserGroupsOptionIncludeUsers(true))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.users = make(map[string]slack.User, len(users))
+
+	for _, user := range users {
+		d.users[user.ID] = user
+	}
+
+	d.channels = make(map[string]slack.Channel, len(channels))
+
+	for _, channel := range channels {
+		d.channels[channel.ID] = channel
+	}
+
+	if usergroupsErr != nil {
+		return errors.WithMessage(usergroupsErr, "unable to retrieve user groups")
+	}
+
+	d.usergroups = make(map[string]slack.UserGroup, len(usergroups))
+
+	for _, usergroup := range usergroups {
+		d.usergroups[usergroup.ID] = usergroup
+	}
+
+	return nil
+}
+
+func (d *Directory) update(data interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch ev := data.(type) {
+	case *slack.ChannelArchiveEvent:
+		delete(d.channels, ev.Channel)
+	case *slack.ChannelCreatedEvent:
+		channel := d.channels[ev.Channel.ID]
+		channel.ID = ev.Channel.ID
+		channel.Name = ev.Channel.Name
+		channel.IsChannel = ev.Channel.IsChannel
+		d.channels[ev.Channel.ID] = channel
+	case *slack.ChannelDeletedEvent:
+		delete(d.channels, ev.Channel)
+	case *slack.ChannelJoinedEvent:
+		d.channels[ev.Channel.ID] = ev.Channel
+	case *slack.ChannelRenameEvent:
+		channel := d.channels[ev.Channel.ID]
+		channel.ID = ev.Channel.ID
+		channel.Name = ev.Channel.Name
+		d.channels[ev.Channel.ID] = channel
+	case *slack.GroupJoinedEvent:
+		d.channels[ev.Channel.ID] = ev.Channel
+	case *slack.GroupRenameEvent:
+		channel := d.channels[ev.Group.ID]
+		channel.ID = ev.Group.ID
+		channel.Name = ev.Group.Name
+		d.channels[ev.Group.ID] = channel
+	case *slack.SubteamCreatedEvent:
+		d.usergroups[ev.Subteam.ID] = ev.Subteam
+	case *slack.SubteamUpdatedEvent:
+		d.usergroups[ev.Subteam.ID] = ev.Subteam
+	case *slack.TeamJoinEvent:
+		d.users[ev.User.ID] = ev.User
+	case *slack.UserChangeEvent:
+		d.users[ev.User.ID] = ev.User
+	}
+}
@@ -0,0 +1,66 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"testing"
+)
+
+func TestChannelID(t *testing.T) {
+	d := newDirectory()
+
+	d.update(&slack.ChannelJoinedEvent{Channel: slack.Channel{GroupConversation: slack.GroupConversation{
+		Conversation: slack.Conversation{ID: "C012345"},
+		Name:         "general",
+	}}})
+
+	d.update(&slack.ChannelRenameEvent{Channel: slack.ChannelRenameInfo{ID: "C678901", Name: "Random"}})
+
+	testCases := []struct {
+		channel  string
+		expected string
+	}{
+		{"C012345", "C012345"},
+		{"#general", "C012345"},
+		{"#random", "C678901"},
+		{"#unknown", "#unknown"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.channel, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := d.ChannelID(testCase.channel); testCase.expected != actual {
+				t.Errorf("ChannelID() was incorrect, got: %s, want %s.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestUserLookups(t *testing.T) {
+	d := newDirectory()
+	user := slack.User{ID: "U012345", Name: "pingu", RealName: "Pingu the Penguin"}
+	user.Profile.DisplayName = "Noot"
+	user.Profile.Email = "pingu@example.com"
+
+	d.update(&slack.TeamJoinEvent{User: user})
+
+	if u, ok := d.User("U012345"); !ok || u.Name != "pingu" {
+		t.Errorf("User() was incorrect, got: %+v.", u)
+	}
+
+	for _, name := range []string{"pingu", "@Pingu", "noot", "Pingu the Penguin"} {
+		if u, ok := d.UserByName(name); !ok || u.ID != "U012345" {
+			t.Errorf("UserByName(%q) was incorrect, got: %+v.", name, u)
+		}
+	}
+
+	if u, ok := d.UserByEmail("PINGU@example.com"); !ok || u.ID != "U012345" {
+		t.Errorf("UserByEmail() was incorrect, got: %+v.", u)
+	}
+
+	if _, ok := d.UserByEmail(""); ok {
+		t.Error("UserByEmail() matched an empty email.")
+	}
+}
@@ -0,0 +1,355 @@
+package pingu
+
+import (
+	"container/list"
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"strings"
+	"sync"
+	"time"
+)
+
+type invocation struct {
+	command     *Command
+	ctx         context.Context
+	edited      bool
+	event       *slack.MessageEvent
+	key         string
+	mu          sync.Mutex
+	plugin      Plugin
+	previous    []MessageRef
+	responses   []MessageRef
+	responseURL string
+}
+
+type responseCache struct {
+	entries  map[string]*list.Element
+	mu       sync.Mutex
+	order    *list.List
+	capacity int
+}
+
+type responseEntry struct {
+	key  string
+	refs []MessageRef
+}
+
+func newResponseCache(capacity int) *responseCache {
+	return &responseCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *responseCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+func (c *responseCache) get(key string) []MessageRef {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+
+	if !ok {
+		return nil
+	}
+
+	c.order.MoveToFront(element)
+
+	return append([]MessageRef(nil), element.Value.(*responseEntry).refs...)
+}
+
+func (c *responseCache) put(key string, refs []MessageRef) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*responseEntry).refs = refs
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&responseEntry{key: key, refs: refs})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*responseEntry).key)
+	}
+}
+
+func (inv *invocation) record(ref MessageRef) {
+	if inv == nil || inv.key == "" || ref.Channel != inv.event.Channel {
+		return
+	}
+
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.responses = append(inv.responses, ref)
+}
+
+// reuse hands out the responses posted for the previous version of an edited
+// message, in order, so that they can be updated in place.
+func (inv *invocation) reuse(ch string) (MessageRef, bool) {
+	if inv == nil || inv.key == "" || ch != inv.event.Channel {
+		return MessageRef{}, false
+	}
+
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if len(inv.previous) == 0 {
+		return MessageRef{}, false
+	}
+
+	ref := inv.previous[0]
+	inv.previous = inv.previous[1:]
+
+	return ref, true
+}
+
+func (p *Pingu) dispatch(ev *slack.MessageEvent) {
+	ev, edited := p.filter(ev)
+
+	if ev == nil {
+		return
+	}
+
+	if text := p.aliases.expand(ev.Text); text != ev.Text {
+		expanded := *ev
+		expanded.Text = text
+		ev = &expanded
+	}
+
+	invocations := make([]*invocation, 0)
+
+	for _, plugin := range p.plugins {
+		if !p.enabled(plugin) {
+			continue
+		}
+
+		ev, mentioned := p.eventFor(plugin, ev)
+
+		for _, command := range plugin.Commands() {
+			if !accepts(command, ev, edited) {
+				continue
+			}
+
+			key := responseKey(ev, p.pluginID(plugin), command)
+
+			if !mentioned || !command.Trigger.MatchString(ev.Text) {
+				if edited && key != "" {
+					go p.retract(key)
+				}
+
+				continue
+			}
+
+			inv := &invocation{
+				command: command,
+				edited:  edited,
+				event:   ev,
+				plugin:  plugin,
+			}
+
+			if command.Accept&AcceptEdits != 0 {
+				inv.key = key
+			}
+
+			if edited && inv.key != "" {
+				inv.previous = p.responses.get(inv.key)
+			}
+
+			invocations = append(invocations, inv)
+		}
+	}
+
+	if len(invocations) == 0 {
+		return
+	}
+
+	if flooding, notice := p.flooding(ev.User); flooding {
+		if notice != "" {
+			go p.ReplyEphemeral(ev, notice)
+		}
+
+		return
+	}
+
+	for _, inv := range invocations {
+		if inv.command.Admin && !p.isAdmin(ev.User) {
+			go p.audit(inv, auditDenied, nil, 0)
+			go p.scoped(inv).ReplyEphemeral(ev, adminMessage)
+			continue
+		}
+
+		if notice := p.restricted(inv.command, ev.Channel); notice != "" {
+			go p.scoped(inv).ReplyEphemeral(ev, notice)
+			continue
+		}
+
+		// Edits update responses that have already been posted, so they
+		// shouldn't be held back by the cooldown the original message started.
+		if !inv.edited {
+			if wait := p.cooldown(inv); wait > 0 {
+				go p.scoped(inv).ReplyEphemeral(ev, cooldownMessage(wait))
+				continue
+			}
+		}
+
+		go p.invoke(inv)
+	}
+}
+
+// filter drops messages that should never reach a command and unwraps edits,
+// returning the message as it reads after the edit.
+func (p *Pingu) filter(ev *slack.MessageEvent) (*slack.MessageEvent, bool) {
+	switch ev.SubType {
+	case "message_deleted":
+		return nil, false
+	case "message_changed":
+		if ev.SubMessage == nil {
+			return nil, false
+		}
+
+		if ev.PreviousMessage != nil && ev.PreviousMessage.Text == ev.SubMessage.Text {
+			return nil, false
+		}
+
+		edited := &slack.MessageEvent{Msg: *ev.SubMessage}
+		edited.Channel = ev.Channel
+
+		if self := p.current().userID(); self != "" && edited.User == self {
+			return nil, false
+		}
+
+		return edited, true
+	}
+
+	if self := p.current().userID(); self != "" && ev.User == self {
+		return nil, false
+	}
+
+	return ev, false
+}
+
+func (p *Pingu) invoke(inv *invocation) {
+	p.pluginLogger(inv.plugin).WithField("trigger", inv.command.Trigger.String()).Info("Command triggered")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pi := p.scoped(inv)
+	started := time.Now()
+
+	err := safely(func() error {
+		return p.handler(inv.plugin)(pi, &Invocation{
+			Command: inv.command,
+			Context: ctx,
+			Event:   inv.event,
+			Plugin:  inv.plugin,
+		})
+	})
+
+	cancel()
+	p.audit(inv, auditOutcome(err), err, time.Since(started))
+
+	if err != nil {
+		p.handleError(pi, inv, err)
+	}
+
+	if inv.key == "" {
+		return
+	}
+
+	for _, ref := range inv.previous {
+		p.Delete(ref)
+	}
+
+	if len(inv.responses) == 0 {
+		p.responses.delete(inv.key)
+		return
+	}
+
+	p.responses.put(inv.key, inv.responses)
+}
+
+func (p *Pingu) handleError(pi *Pingu, inv *invocation, err error) {
+	fields := logrus.Fields{
+		"plugin":  inv.plugin.Name(),
+		"trigger": inv.command.Trigger.String(),
+	}
+
+	if userErr, ok := asUserError(err); ok {
+		p.pluginLogger(inv.plugin).WithFields(fields).WithField("error", err).Info("Command rejected")
+		pi.Reply(inv.event, userErr.Message())
+
+		return
+	}
+
+	p.fail(p.commandKey(inv.plugin, inv.command), fields, err, "Command failed")
+	pi.Reply(inv.event, "Noot! Noot! Something went wrong, please try again later.")
+}
+
+func (p *Pingu) retract(key string) {
+	for _, ref := range p.responses.get(key) {
+		p.Delete(ref)
+	}
+
+	p.responses.delete(key)
+}
+
+func accepts(command *Command, ev *slack.MessageEvent, edited bool) bool {
+	if edited && command.Accept&AcceptEdits == 0 {
+		return false
+	}
+
+	if ev.SubType == "thread_broadcast" && command.Accept&AcceptBroadcasts == 0 {
+		return false
+	}
+
+	if (ev.BotID != "" || ev.SubType == "bot_message") && command.Accept&AcceptBots == 0 {
+		return false
+	}
+
+	return true
+}
+
+// restricted checks whether the command may be used in the channel, returning
+// a notice listing the channels it is available in if it may not. Commands
+// without any channels are available everywhere.
+func (p *Pingu) restricted(command *Command, ch string) string {
+	if len(command.Channels) == 0 {
+		return ""
+	}
+
+	mentions := make([]string, 0, len(command.Channels))
+
+	for _, allowed := range command.Channels {
+		ref := p.resolve(allowed)
+
+		if ref.Channel == ch && ref.Workspace == p.current().name {
+			return ""
+		}
+
+		mentions = append(mentions, "<#"+ref.Channel+">")
+	}
+
+	return "Noot! Noot! That command is only available in " + strings.Join(mentions, ", ") + "!"
+}
+
+func responseKey(ev *slack.MessageEvent, id string, command *Command) string {
+	if ev.Timestamp == "" {
+		return ""
+	}
+
+	return ev.Channel + "/" + ev.Timestamp + "/" + id + "/" + command.Trigger.String()
+}
@@ -0,0 +1,119 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"testing"
+)
+
+func TestAccepts(t *testing.T) {
+	bot := &slack.MessageEvent{Msg: slack.Msg{BotID: "B012345"}}
+	broadcast := &slack.MessageEvent{Msg: slack.Msg{SubType: "thread_broadcast"}}
+	plain := &slack.MessageEvent{Msg: slack.Msg{User: "U012345"}}
+
+	testCases := []struct {
+		name     string
+		accept   Accept
+		event    *slack.MessageEvent
+		edited   bool
+		expected bool
+	}{
+		{"plain", 0, plain, false, true},
+		{"edit", 0, plain, true, false},
+		{"edit accepted", AcceptEdits, plain, true, true},
+		{"bot", 0, bot, false, false},
+		{"bot accepted", AcceptBots, bot, false, true},
+		{"broadcast", AcceptEdits, broadcast, false, false},
+		{"broadcast accepted", AcceptBroadcasts, broadcast, false, true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			command := &Command{Accept: testCase.accept}
+
+			if actual := accepts(command, testCase.event, testCase.edited); testCase.expected != actual {
+				t.Errorf("accepts() was incorrect, got: %v, want %v.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	p := &Pingu{state: &state{workspaces: []*workspace{{self: "U000001"}}}}
+
+	edit := &slack.MessageEvent{
+		Msg:             slack.Msg{Channel: "C012345", SubType: "message_changed"},
+		SubMessage:      &slack.Msg{Text: "ABC-2", Timestamp: "1600000000.000100", User: "U012345"},
+		PreviousMessage: &slack.Msg{Text: "ABC-1", Timestamp: "1600000000.000100", User: "U012345"},
+	}
+	unfurl := &slack.MessageEvent{
+		Msg:             slack.Msg{Channel: "C012345", SubType: "message_changed"},
+		SubMessage:      &slack.Msg{Text: "ABC-1", User: "U012345"},
+		PreviousMessage: &slack.Msg{Text: "ABC-1", User: "U012345"},
+	}
+
+	testCases := []struct {
+		name     string
+		event    *slack.MessageEvent
+		expected string
+		edited   bool
+	}{
+		{"plain", &slack.MessageEvent{Msg: slack.Msg{Text: "ABC-1", User: "U012345"}}, "ABC-1", false},
+		{"self", &slack.MessageEvent{Msg: slack.Msg{Text: "ABC-1", User: "U000001"}}, "", false},
+		{"deleted", &slack.MessageEvent{Msg: slack.Msg{SubType: "message_deleted"}}, "", false},
+		{"edit", edit, "ABC-2", true},
+		{"unfurl", unfurl, "", false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			ev, edited := p.filter(testCase.event)
+
+			if testCase.expected == "" {
+				if ev != nil {
+					t.Errorf("filter() was incorrect, got: %+v, want nil.", ev)
+				}
+
+				return
+			}
+
+			if ev == nil || ev.Text != testCase.expected || edited != testCase.edited {
+				t.Fatalf("filter() was incorrect, got: %+v (edited: %v), want %s (edited: %v).", ev, edited, testCase.expected, testCase.edited)
+			}
+
+			if ev.Channel != "C012345" && testCase.edited {
+				t.Errorf("filter() did not keep the channel of the edited message, got: %s.", ev.Channel)
+			}
+		})
+	}
+}
+
+func TestRestricted(t *testing.T) {
+	t.Parallel()
+
+	p := &Pingu{state: &state{workspaces: []*workspace{{directory: newDirectory(), name: defaultWorkspace}}}}
+
+	testCases := []struct {
+		channels []string
+		ch       string
+		expected string
+	}{
+		{nil, "C012345", ""},
+		{[]string{"C012345"}, "C012345", ""},
+		{[]string{"C012345", "C678901"}, "C678901", ""},
+		{[]string{"C012345"}, "C678901", "Noot! Noot! That command is only available in <#C012345>!"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := p.restricted(&Command{Channels: testCase.channels}, testCase.ch); actual != testCase.expected {
+			t.Errorf("restricted(%v, %s) was incorrect, got: %q, want %q.", testCase.channels, testCase.ch, actual, testCase.expected)
+		}
+	}
+}
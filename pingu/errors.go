@@ -0,0 +1,95 @@
+package pingu
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"runtime/debug"
+	"time"
+)
+
+// UserError is an error caused by how a command was used rather than by
+// something going wrong, and its message is meant to be shown to the user.
+type UserError struct {
+	err     error
+	message string
+}
+
+func NewUserError(format string, a ...interface{}) error {
+	return &UserError{message: fmt.Sprintf(format, a...)}
+}
+
+func WrapUserError(err error, format string, a ...interface{}) error {
+	return &UserError{err: err, message: fmt.Sprintf(format, a...)}
+}
+
+func (e *UserError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *UserError) Message() string {
+	return e.message
+}
+
+func (e *UserError) Unwrap() error {
+	return e.err
+}
+
+func (p *Pingu) Failures() map[string]int {
+	p.failuresMu.Lock()
+	defer p.failuresMu.Unlock()
+
+	failures := make(map[string]int, len(p.failures))
+
+	for key, count := range p.failures {
+		failures[key] = count
+	}
+
+	return failures
+}
+
+// fail records and logs an internal error, and reports it to the ops channel.
+func (p *Pingu) fail(key string, fields logrus.Fields, err error, message string) {
+	p.failuresMu.Lock()
+	p.failures[key]++
+	p.failuresMu.Unlock()
+
+	p.logger.WithFields(fields).WithField("error", err).Error(message)
+
+	p.reporter.report(key, err, time.Now())
+}
+
+func asUserError(err error) (*UserError, bool) {
+	for err != nil {
+		if userErr, ok := err.(*UserError); ok {
+			return userErr, true
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// safely calls fn, turning a panic into an error so that a misbehaving plugin
+// can't take the whole bot down with it.
+func safely(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return fn()
+}
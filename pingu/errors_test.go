@@ -0,0 +1,58 @@
+package pingu
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"testing"
+)
+
+func TestAsUserError(t *testing.T) {
+	userErr := NewUserError("Noot! Noot! %d does not have a leaderboard!", 2014)
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"user error", userErr, "Noot! Noot! 2014 does not have a leaderboard!"},
+		{"wrapped", errors.WithMessage(userErr, "unable to post leaderboard"), "Noot! Noot! 2014 does not have a leaderboard!"},
+		{"wrapped by fmt", fmt.Errorf("unable to post leaderboard: %w", userErr), "Noot! Noot! 2014 does not have a leaderboard!"},
+		{"with cause", WrapUserError(errors.New("404 Not Found"), "I was unable to retrieve ABC-1."), "I was unable to retrieve ABC-1."},
+		{"internal", errors.New("connection refused"), ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, ok := asUserError(testCase.err)
+
+			if ok != (testCase.expected != "") {
+				t.Fatalf("asUserError() was incorrect, got: %v, want %v.", ok, testCase.expected != "")
+			}
+
+			if ok && actual.Message() != testCase.expected {
+				t.Errorf("asUserError() was incorrect, got: %s, want %s.", actual.Message(), testCase.expected)
+			}
+		})
+	}
+}
+
+func TestSafely(t *testing.T) {
+	err := safely(func() error {
+		panic("noot")
+	})
+
+	if err == nil || !strings.HasPrefix(err.Error(), "panic: noot") {
+		t.Errorf("safely() was incorrect, got: %v, want a panic error.", err)
+	}
+
+	expected := errors.New("noot")
+
+	if err := safely(func() error { return expected }); err != expected {
+		t.Errorf("safely() was incorrect, got: %v, want %v.", err, expected)
+	}
+}
@@ -0,0 +1,110 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+)
+
+type ChannelJoinedHandler interface {
+	OnChannelJoined(pi *Pingu, ev *slack.ChannelJoinedEvent)
+}
+
+type FileSharedHandler interface {
+	OnFileShared(pi *Pingu, ev *slack.FileSharedEvent)
+}
+
+type MemberJoinedChannelHandler interface {
+	OnMemberJoinedChannel(pi *Pingu, ev *slack.MemberJoinedChannelEvent)
+}
+
+type MemberLeftChannelHandler interface {
+	OnMemberLeftChannel(pi *Pingu, ev *slack.MemberLeftChannelEvent)
+}
+
+type PinAddedHandler interface {
+	OnPinAdded(pi *Pingu, ev *slack.PinAddedEvent)
+}
+
+type PinRemovedHandler interface {
+	OnPinRemoved(pi *Pingu, ev *slack.PinRemovedEvent)
+}
+
+type ReactionAddedHandler interface {
+	OnReactionAdded(pi *Pingu, ev *slack.ReactionAddedEvent)
+}
+
+type ReactionRemovedHandler interface {
+	OnReactionRemoved(pi *Pingu, ev *slack.ReactionRemovedEvent)
+}
+
+type TeamJoinHandler interface {
+	OnTeamJoin(pi *Pingu, ev *slack.TeamJoinEvent)
+}
+
+type UserChangeHandler interface {
+	OnUserChange(pi *Pingu, ev *slack.UserChangeEvent)
+}
+
+func (p *Pingu) dispatchEvent(msg slack.RTMEvent) {
+	for _, plugin := range p.plugins {
+		if !p.enabled(plugin) {
+			continue
+		}
+
+		if handler := eventHandler(plugin, msg.Data); handler != nil {
+			go p.handleEvent(plugin, msg.Type, handler)
+		}
+	}
+}
+
+func (p *Pingu) handleEvent(plugin Plugin, event string, handler func(pi *Pingu)) {
+	p.pluginLogger(plugin).WithField("event", event).Info("Event handled")
+
+	handler(p.scoped(&invocation{plugin: plugin}))
+}
+
+func eventHandler(plugin Plugin, data interface{}) func(pi *Pingu) {
+	switch ev := data.(type) {
+	case *slack.ChannelJoinedEvent:
+		if h, ok := plugin.(ChannelJoinedHandler); ok {
+			return func(pi *Pingu) { h.OnChannelJoined(pi, ev) }
+		}
+	case *slack.FileSharedEvent:
+		if h, ok := plugin.(FileSharedHandler); ok {
+			return func(pi *Pingu) { h.OnFileShared(pi, ev) }
+		}
+	case *slack.MemberJoinedChannelEvent:
+		if h, ok := plugin.(MemberJoinedChannelHandler); ok {
+			return func(pi *Pingu) { h.OnMemberJoinedChannel(pi, ev) }
+		}
+	case *slack.MemberLeftChannelEvent:
+		if h, ok := plugin.(MemberLeftChannelHandler); ok {
+			return func(pi *Pingu) { h.OnMemberLeftChannel(pi, ev) }
+		}
+	case *slack.PinAddedEvent:
+		if h, ok := plugin.(PinAddedHandler); ok {
+			return func(pi *Pingu) { h.OnPinAdded(pi, ev) }
+		}
+	case *slack.PinRemovedEvent:
+		if h, ok := plugin.(PinRemovedHandler); ok {
+			return func(pi *Pingu) { h.OnPinRemoved(pi, ev) }
+		}
+	case *slack.ReactionAddedEvent:
+		if h, ok := plugin.(ReactionAddedHandler); ok {
+			return func(pi *Pingu) { h.OnReactionAdded(pi, ev) }
+		}
+	case *slack.ReactionRemovedEvent:
+		if h, ok := plugin.(ReactionRemovedHandler); ok {
+			return func(pi *Pingu) { h.OnReactionRemoved(pi, ev) }
+		}
+	case *slack.TeamJoinEvent:
+		if h, ok := plugin.(TeamJoinHandler); ok {
+			return func(pi *Pingu) { h.OnTeamJoin(pi, ev) }
+		}
+	case *slack.UserChangeEvent:
+		if h, ok := plugin.(UserChangeHandler); ok {
+			return func(pi *Pingu) { h.OnUserChange(pi, ev) }
+		}
+	}
+
+	return nil
+}
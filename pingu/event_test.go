@@ -0,0 +1,39 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"testing"
+)
+
+type reactionPlugin struct {
+	Plugin
+	reactions []string
+}
+
+func (pl *reactionPlugin) OnReactionAdded(pi *Pingu, ev *slack.ReactionAddedEvent) {
+	pl.reactions = append(pl.reactions, ev.Reaction)
+}
+
+func TestEventHandler(t *testing.T) {
+	pl := &reactionPlugin{}
+
+	if handler := eventHandler(pl, &slack.ReactionRemovedEvent{}); handler != nil {
+		t.Errorf("eventHandler() was incorrect, got a handler for an unsubscribed event.")
+	}
+
+	if handler := eventHandler(pl, &slack.HelloEvent{}); handler != nil {
+		t.Errorf("eventHandler() was incorrect, got a handler for an unsupported event.")
+	}
+
+	handler := eventHandler(pl, &slack.ReactionAddedEvent{Reaction: "penguin"})
+
+	if handler == nil {
+		t.Fatalf("eventHandler() was incorrect, got no handler for a subscribed event.")
+	}
+
+	handler(nil)
+
+	if len(pl.reactions) != 1 || pl.reactions[0] != "penguin" {
+		t.Errorf("eventHandler() was incorrect, got: %v, want %v.", pl.reactions, []string{"penguin"})
+	}
+}
@@ -0,0 +1,205 @@
+package pingu
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Route is an HTTP endpoint. Like everything else that acts on what it
+// receives, routes are only served by the leader, and other replicas respond
+// with 503 Service Unavailable.
+type Route struct {
+	Func   func(pi *Pingu, w http.ResponseWriter, r *http.Request)
+	Method string
+	Path   string
+
+	// standby routes are served by every replica.
+	standby bool
+}
+
+type Routes []*Route
+
+type Router interface {
+	Routes() Routes
+}
+
+func (p *Pingu) handle(mux *http.ServeMux, paths map[string]string, plugin Plugin, routes Routes) {
+	owner := p.name
+	prefix := ""
+
+	if plugin != nil {
+		owner = plugin.Name()
+
+		if namespace := p.Namespace(plugin); namespace != "" {
+			prefix = "/" + namespace
+		}
+	}
+
+	for _, route := range routes {
+		route := &Route{Func: route.Func, Method: route.Method, Path: prefix + route.Path, standby: route.standby}
+
+		if registeredBy, ok := paths[route.Path]; ok {
+			p.logger.WithFields(logrus.Fields{
+				"owner":  registeredBy,
+				"path":   route.Path,
+				"plugin": owner,
+			}).Fatal("Route already registered")
+		}
+
+		paths[route.Path] = owner
+
+		mux.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
+			if route.Method != "" && r.Method != route.Method {
+				w.Header().Set("Allow", route.Method)
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+				return
+			}
+
+			if !route.standby && !p.Leader() {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+
+			p.logger.WithFields(logrus.Fields{
+				"method": r.Method,
+				"path":   r.URL.Path,
+				"plugin": owner,
+			}).Info("Route requested")
+
+			var inv *invocation
+
+			if plugin != nil {
+				inv = &invocation{plugin: plugin}
+			}
+
+			route.Func(p.scoped(inv), w, r)
+		})
+	}
+}
+
+func (p *Pingu) routes() Routes {
+	healthPath := p.config.GetString("pingu.health_path")
+
+	if healthPath == "" {
+		healthPath = "/healthz"
+	}
+
+	routes := Routes{
+		&Route{
+			Func:    p.health,
+			Method:  http.MethodGet,
+			Path:    healthPath,
+			standby: true,
+		},
+	}
+
+	if p.config.GetString("slack.signing_secret") == "" {
+		return routes
+	}
+
+	commandsPath := p.config.GetString("slack.commands_path")
+
+	if commandsPath == "" {
+		commandsPath = "/slack/commands"
+	}
+
+	interactionsPath := p.config.GetString("slack.interactions_path")
+
+	if interactionsPath == "" {
+		interactionsPath = "/slack/interactions"
+	}
+
+	return append(
+		routes,
+		&Route{
+			Func:   p.receiveSlashCommand,
+			Method: http.MethodPost,
+			Path:   commandsPath,
+		},
+		&Route{
+			Func:   p.receiveInteraction,
+			Method: http.MethodPost,
+			Path:   interactionsPath,
+		},
+	)
+}
+
+func (p *Pingu) serve() {
+	address := p.config.GetString("pingu.http_address")
+
+	if address == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	paths := make(map[string]string)
+
+	p.handle(mux, paths, nil, p.routes())
+
+	for _, plugin := range p.plugins {
+		if router, ok := plugin.(Router); ok {
+			p.handle(mux, paths, plugin, router.Routes())
+		}
+	}
+
+	p.logger.WithField("address", address).Info("HTTP server started")
+
+	if err := http.ListenAndServe(address, mux); err != nil {
+		p.logger.Fatal(err)
+	}
+}
+
+// health reports whether each workspace is connected and whether this replica
+// is the leader. Asking for ?leader responds with a 503 unless it is, so that
+// a load balancer can send everything to the leader.
+func (p *Pingu) health(pi *Pingu, w http.ResponseWriter, r *http.Request) {
+	status := struct {
+		Connected map[string]bool `json:"connected"`
+		Instance  string          `json:"instance,omitempty"`
+		Leader    bool            `json:"leader"`
+	}{
+		Connected: make(map[string]bool, len(p.workspaces)),
+		Leader:    p.Leader(),
+	}
+
+	for _, ws := range p.workspaces {
+		status.Connected[ws.name] = ws.online()
+	}
+
+	if p.election != nil {
+		status.Instance = p.election.id
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, ok := r.URL.Query()["leader"]; ok && !status.Leader {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(status)
+}
+
+func (p *Pingu) verifyRequest(r *http.Request) ([]byte, error) {
+	verifier, err := slack.NewSecretsVerifier(r.Header, p.config.GetString("slack.signing_secret"))
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to verify request")
+	}
+
+	body, err := ioutil.ReadAll(io.TeeReader(io.LimitReader(r.Body, 1<<20), &verifier))
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to read request body")
+	}
+
+	if err := verifier.Ensure(); err != nil {
+		return nil, errors.WithMessage(err, "signature verification failed")
+	}
+
+	return body, nil
+}
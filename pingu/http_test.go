@@ -0,0 +1,53 @@
+package pingu
+
+import (
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleStandby(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	p := &Pingu{state: &state{
+		election: newElection("", "standby", time.Minute, nil),
+		logger:   logger,
+	}}
+
+	ok := func(pi *Pingu, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	mux := http.NewServeMux()
+	p.handle(mux, make(map[string]string), nil, Routes{
+		&Route{Func: ok, Path: "/slack/commands"},
+		&Route{Func: ok, Path: "/healthz", standby: true},
+	})
+
+	testCases := []struct {
+		path     string
+		leading  bool
+		expected int
+	}{
+		{"/slack/commands", false, http.StatusServiceUnavailable},
+		{"/healthz", false, http.StatusOK},
+		{"/slack/commands", true, http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		p.election.set(testCase.leading)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, testCase.path, nil))
+
+		if w.Code != testCase.expected {
+			t.Errorf("%s was incorrect while leading is %v, got: %d, want %d.", testCase.path, testCase.leading, w.Code, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+)
+
+// instance is one of several instances of the same plugin. The first instance
+// of every plugin is named after its file and isn't namespaced, while any
+// additional ones are configured as plugins.<name> with a plugin key naming
+// the file they're instances of.
+type instance struct {
+	first     bool
+	name      string
+	namespace string
+}
+
+// instanceNames returns the names of the additional instances of the plugin
+// loaded by the factory.
+func instanceNames(config *viper.Viper, factory *Factory) []string {
+	names := make([]string, 0)
+
+	for name := range config.GetStringMap("plugins") {
+		if name != factory.Name && config.GetString("plugins."+name+".plugin") == factory.Name {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// newPlugins creates the first instance of every plugin, followed by any
+// additional ones. Every instance is identified by its name, which can't be in
+// use by any other plugin, or they would share their state.
+func newPlugins(config *viper.Viper, factories []*Factory, logger *logrus.Logger) (Plugins, map[Plugin]*instance, error) {
+	instances := make(map[Plugin]*instance)
+	plugins := make(Plugins, 0, len(factories))
+	ids := map[string]bool{slug((&adminPlugin{}).Name()): true}
+
+	for _, factory := range factories {
+		ids[slug(factory.Name)] = true
+	}
+
+	for _, factory := range factories {
+		plugin := factory.New(pluginConfig(config, factory, factory.Name, logger))
+		plugins = append(plugins, plugin)
+		instances[plugin] = &instance{first: true, name: factory.Name}
+
+		logger.WithFields(logrus.Fields{
+			"author":  plugin.Author(),
+			"name":    plugin.Name(),
+			"version": plugin.Version(),
+		}).Info("Plugin loaded")
+	}
+
+	for _, factory := range factories {
+		for _, name := range instanceNames(config, factory) {
+			if ids[slug(name)] {
+				return nil, nil, errors.Errorf("plugin instance %s is named like another plugin", name)
+			}
+
+			ids[slug(name)] = true
+			plugin := factory.New(pluginConfig(config, factory, name, logger))
+			plugins = append(plugins, plugin)
+			instances[plugin] = newInstance(config, name)
+
+			logger.WithFields(logrus.Fields{
+				"author":   plugin.Author(),
+				"instance": name,
+				"name":     plugin.Name(),
+				"version":  plugin.Version(),
+			}).Info("Plugin loaded")
+		}
+	}
+
+	return plugins, instances, nil
+}
+
+func newInstance(config *viper.Viper, name string) *instance {
+	namespace := config.GetString("plugins." + name + ".namespace")
+
+	if namespace == "" {
+		namespace = name
+	}
+
+	return &instance{name: name, namespace: namespace}
+}
+
+// Instance returns the name of the plugin's instance, or nothing for the first
+// instance of every plugin.
+func (p *Pingu) Instance(plugin Plugin) string {
+	if inst, ok := p.instances[plugin]; ok && !inst.first {
+		return inst.name
+	}
+
+	return ""
+}
+
+// Namespace returns the prefix that the plugin's commands have to be invoked
+// with, as in "!work.leaderboard", or nothing if it isn't namespaced.
+func (p *Pingu) Namespace(plugin Plugin) string {
+	if inst, ok := p.instances[plugin]; ok {
+		return inst.namespace
+	}
+
+	return ""
+}
+
+// eventFor returns the message as the plugin should see it, with its namespace
+// removed from every command, and whether it was meant for the plugin at all.
+func (p *Pingu) eventFor(plugin Plugin, ev *slack.MessageEvent) (*slack.MessageEvent, bool) {
+	namespace := p.Namespace(plugin)
+
+	if namespace == "" {
+		return ev, true
+	}
+
+	text := strings.Replace(ev.Text, "!"+namespace+".", "!", -1)
+
+	if text == ev.Text {
+		return ev, false
+	}
+
+	stripped := *ev
+	stripped.Text = text
+
+	return &stripped, true
+}
+
+// pluginID identifies the plugin in keys, logs and configuration by the name of
+// its instance, which is the name of its file for the first one. Plugins that
+// weren't loaded from a file, such as the admin plugin, fall back to their own
+// name.
+func (p *Pingu) pluginID(plugin Plugin) string {
+	if inst, ok := p.instances[plugin]; ok {
+		return slug(inst.name)
+	}
+
+	return slug(plugin.Name())
+}
+
+// Slash returns the slash command that invokes the command, which for
+// namespaced plugins is prefixed with the namespace, as in "/work-leaderboard".
+func (p *Pingu) Slash(plugin Plugin, command *Command) string {
+	if namespace := p.Namespace(plugin); namespace != "" && command.Slash != "" {
+		return "/" + namespace + "-" + strings.TrimPrefix(command.Slash, "/")
+	}
+
+	return command.Slash
+}
@@ -0,0 +1,141 @@
+package pingu
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestInstanceNames(t *testing.T) {
+	t.Parallel()
+
+	config := viper.New()
+	config.Set("plugins.aoc.session", "default")
+	config.Set("plugins.work.plugin", "aoc")
+	config.Set("plugins.community.plugin", "aoc")
+	config.Set("plugins.tickets.plugin", "jira")
+
+	expected := []string{"community", "work"}
+
+	if actual := instanceNames(config, &Factory{Name: "aoc"}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("instanceNames() was incorrect, got: %v, want %v.", actual, expected)
+	}
+}
+
+func TestEventFor(t *testing.T) {
+	t.Parallel()
+
+	plugin := &taskPlugin{}
+	p := &Pingu{state: &state{instances: map[Plugin]*instance{
+		plugin: {name: "work", namespace: "work"},
+	}}}
+
+	testCases := []struct {
+		text      string
+		expected  string
+		mentioned bool
+	}{
+		{"!leaderboard", "!leaderboard", false},
+		{"!work.leaderboard 2019", "!leaderboard 2019", true},
+		{"!community.leaderboard", "!community.leaderboard", false},
+	}
+
+	for _, testCase := range testCases {
+		ev := &slack.MessageEvent{Msg: slack.Msg{Text: testCase.text}}
+		actual, mentioned := p.eventFor(plugin, ev)
+
+		if actual.Text != testCase.expected || mentioned != testCase.mentioned {
+			t.Errorf("eventFor(%q) was incorrect, got: %q/%v, want %q/%v.", testCase.text, actual.Text, mentioned, testCase.expected, testCase.mentioned)
+		}
+
+		if ev.Text != testCase.text {
+			t.Errorf("eventFor(%q) should not modify the original event.", testCase.text)
+		}
+	}
+
+	if _, mentioned := p.eventFor(&adminPlugin{}, &slack.MessageEvent{}); !mentioned {
+		t.Errorf("eventFor() was incorrect, plugins without a namespace should see every message.")
+	}
+}
+
+func TestPluginID(t *testing.T) {
+	t.Parallel()
+
+	plugin := &taskPlugin{}
+	first := &taskPlugin{}
+	p := &Pingu{state: &state{instances: map[Plugin]*instance{
+		plugin: {name: "Work", namespace: "w"},
+		first:  {first: true, name: "aoc"},
+	}}}
+
+	testCases := []struct {
+		plugin   Plugin
+		expected string
+	}{
+		{plugin, "work"},
+		{first, "aoc"},
+		{&adminPlugin{}, "admin"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := p.pluginID(testCase.plugin); actual != testCase.expected {
+			t.Errorf("pluginID() was incorrect, got: %s, want %s.", actual, testCase.expected)
+		}
+	}
+
+	if actual := p.Instance(first); actual != "" {
+		t.Errorf("Instance() was incorrect, got: %s, want nothing for the first instance.", actual)
+	}
+
+	if actual := p.Slash(plugin, &Command{Slash: "/leaderboard"}); actual != "/w-leaderboard" {
+		t.Errorf("slash() was incorrect, got: %s, want %s.", actual, "/w-leaderboard")
+	}
+
+	if actual := p.Slash(&taskPlugin{}, &Command{Slash: "/leaderboard"}); actual != "/leaderboard" {
+		t.Errorf("slash() was incorrect, got: %s, want %s.", actual, "/leaderboard")
+	}
+}
+
+func TestNewPlugins(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	newPlugin := func(*viper.Viper) Plugin {
+		return &taskPlugin{Plugin: &adminPlugin{}}
+	}
+
+	factories := []*Factory{
+		{Config: &PluginConfig{}, Name: "aoc", New: newPlugin},
+		{Config: &PluginConfig{}, Name: "jira", New: newPlugin},
+	}
+
+	testCases := []struct {
+		name    string
+		plugins int
+		fails   bool
+	}{
+		{"work", 3, false},
+		{"Jira", 0, true},
+		{"admin", 0, true},
+	}
+
+	for _, testCase := range testCases {
+		config := viper.New()
+		config.Set("plugins."+testCase.name+".plugin", "aoc")
+
+		plugins, instances, err := newPlugins(config, factories, logger)
+
+		if (err != nil) != testCase.fails {
+			t.Errorf("newPlugins() returned an unexpected error for %s: %v.", testCase.name, err)
+		}
+
+		if len(plugins) != testCase.plugins || len(instances) != testCase.plugins {
+			t.Errorf("newPlugins() was incorrect for %s, got: %d/%d plugins/instances.", testCase.name, len(plugins), len(instances))
+		}
+	}
+}
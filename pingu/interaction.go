@@ -0,0 +1,187 @@
+package pingu
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"net/http"
+	"net/url"
+)
+
+type Callback struct {
+	Func func(pi *Pingu, in *Interaction)
+	ID   string
+}
+
+type Callbacks []*Callback
+
+type Interactive interface {
+	Callbacks() Callbacks
+}
+
+type Interaction struct {
+	ActionID   string
+	CallbackID string
+	Payload    *slack.InteractionCallback
+	User       slack.User
+	Value      string
+	Values     map[string]map[string]slack.BlockAction
+}
+
+func (in *Interaction) matches(id string) bool {
+	return id != "" && (id == in.CallbackID || id == in.ActionID)
+}
+
+func (p *Pingu) DeleteOriginal(in *Interaction) error {
+	return p.queue.send(in.Payload.Channel.ID, func() error {
+		_, _, _, err := p.current().client.SendMessage(
+			in.Payload.Channel.ID,
+			slack.MsgOptionDeleteOriginal(in.Payload.ResponseURL),
+		)
+
+		return err
+	})
+}
+
+func (p *Pingu) OpenModal(in *Interaction, view slack.ModalViewRequest) error {
+	_, err := p.current().client.OpenView(in.Payload.TriggerID, view)
+
+	return err
+}
+
+func (p *Pingu) UpdateOriginal(in *Interaction, msg RichMessage) error {
+	return p.queue.send(in.Payload.Channel.ID, func() error {
+		_, _, _, err := p.current().client.SendMessage(
+			in.Payload.Channel.ID,
+			append(msg.options(), slack.MsgOptionReplaceOriginal(in.Payload.ResponseURL))...,
+		)
+
+		return err
+	})
+}
+
+func (p *Pingu) dispatchInteraction(payload *slack.InteractionCallback) {
+	for _, in := range newInteractions(payload) {
+		for _, plugin := range p.plugins {
+			interactive, ok := plugin.(Interactive)
+
+			if !ok || !p.enabled(plugin) {
+				continue
+			}
+
+			for _, callback := range interactive.Callbacks() {
+				if !in.matches(callback.ID) {
+					continue
+				}
+
+				p.pluginLogger(plugin).WithFields(logrus.Fields{
+					"callback": callback.ID,
+					"type":     payload.Type,
+				}).Info("Interaction triggered")
+
+				callback.Func(p.scoped(&invocation{plugin: plugin}), in)
+			}
+		}
+	}
+}
+
+func (p *Pingu) receiveInteraction(pi *Pingu, w http.ResponseWriter, r *http.Request) {
+	body, err := p.verifyRequest(r)
+
+	if err != nil {
+		p.logger.WithField("remote", r.RemoteAddr).Warn(err)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	values, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var payload slack.InteractionCallback
+
+	if err := json.Unmarshal([]byte(values.Get("payload")), &payload); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects an acknowledgement within three seconds, so handlers
+	// are run after the response has been written.
+	w.WriteHeader(http.StatusOK)
+
+	go p.on(p.workspaceByTeam(payload.Team.ID)).dispatchInteraction(&payload)
+}
+
+func newInteractions(payload *slack.InteractionCallback) []*Interaction {
+	interactions := make([]*Interaction, 0)
+
+	switch payload.Type {
+	case slack.InteractionTypeBlockActions:
+		for _, action := range payload.ActionCallback.BlockActions {
+			interactions = append(interactions, &Interaction{
+				ActionID:   action.ActionID,
+				CallbackID: action.BlockID,
+				Payload:    payload,
+				User:       payload.User,
+				Value:      blockActionValue(action),
+			})
+		}
+	case slack.InteractionTypeInteractionMessage:
+		for _, action := range payload.ActionCallback.AttachmentActions {
+			value := action.Value
+
+			if len(action.SelectedOptions) > 0 {
+				value = action.SelectedOptions[0].Value
+			}
+
+			interactions = append(interactions, &Interaction{
+				ActionID:   action.Name,
+				CallbackID: payload.CallbackID,
+				Payload:    payload,
+				User:       payload.User,
+				Value:      value,
+			})
+		}
+	case slack.InteractionTypeViewSubmission, slack.InteractionTypeViewClosed:
+		in := &Interaction{
+			CallbackID: payload.View.CallbackID,
+			Payload:    payload,
+			User:       payload.User,
+			Value:      payload.View.PrivateMetadata,
+		}
+
+		if payload.View.State != nil {
+			in.Values = payload.View.State.Values
+		}
+
+		interactions = append(interactions, in)
+	default:
+		interactions = append(interactions, &Interaction{
+			CallbackID: payload.CallbackID,
+			Payload:    payload,
+			User:       payload.User,
+		})
+	}
+
+	return interactions
+}
+
+func blockActionValue(action *slack.BlockAction) string {
+	switch {
+	case action.Value != "":
+		return action.Value
+	case action.SelectedOption.Value != "":
+		return action.SelectedOption.Value
+	case action.SelectedUser != "":
+		return action.SelectedUser
+	case action.SelectedChannel != "":
+		return action.SelectedChannel
+	case action.SelectedConversation != "":
+		return action.SelectedConversation
+	default:
+		return action.SelectedDate
+	}
+}
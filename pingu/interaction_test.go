@@ -0,0 +1,68 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"testing"
+)
+
+func TestInteractionMatches(t *testing.T) {
+	in := &Interaction{
+		ActionID:   "vote_yes",
+		CallbackID: "poll",
+	}
+
+	testCases := []struct {
+		id       string
+		expected bool
+	}{
+		{"poll", true},
+		{"vote_yes", true},
+		{"vote_no", false},
+		{"", false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.id, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := in.matches(testCase.id); testCase.expected != actual {
+				t.Errorf("matches() was incorrect, got: %v, want %v.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestNewInteractions(t *testing.T) {
+	payload := &slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockActions,
+		User: slack.User{ID: "U012345"},
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{
+				{ActionID: "vote_yes", BlockID: "poll", Value: "yes"},
+				{ActionID: "pick", BlockID: "poll", SelectedOption: slack.OptionBlockObject{Value: "maybe"}},
+			},
+		},
+	}
+
+	interactions := newInteractions(payload)
+
+	if len(interactions) != 2 {
+		t.Fatalf("newInteractions() was incorrect, got: %d interactions, want %d.", len(interactions), 2)
+	}
+
+	for i, expected := range []string{"yes", "maybe"} {
+		if actual := interactions[i].Value; actual != expected {
+			t.Errorf("newInteractions() was incorrect, got: %v, want %v.", actual, expected)
+		}
+
+		if actual := interactions[i].CallbackID; actual != "poll" {
+			t.Errorf("newInteractions() was incorrect, got: %v, want %v.", actual, "poll")
+		}
+
+		if actual := interactions[i].User.ID; actual != "U012345" {
+			t.Errorf("newInteractions() was incorrect, got: %v, want %v.", actual, "U012345")
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package pingu
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type lease struct {
+	Expires time.Time `json:"expires"`
+	Holder  string    `json:"holder"`
+}
+
+// election decides which of several replicas sharing a data path is the
+// leader, through a lease that the leader has to keep renewing. Should it stop
+// doing so, another replica takes over once the lease has expired.
+type election struct {
+	duration time.Duration
+	id       string
+	leading  int32
+	onChange func(leading bool)
+	path     string
+}
+
+func newElection(path string, id string, duration time.Duration, onChange func(leading bool)) *election {
+	if duration <= 0 {
+		duration = 15 * time.Second
+	}
+
+	return &election{
+		duration: duration,
+		id:       id,
+		onChange: onChange,
+		path:     path,
+	}
+}
+
+// campaign acquires or renews the lease, if it's free or already held by this
+// replica, and reports whether this replica is the leader.
+func (e *election) campaign(now time.Time) (bool, error) {
+	leading := false
+
+	err := e.locked(func(current lease) (lease, bool) {
+		if current.Holder != e.id && now.Before(current.Expires) {
+			return current, false
+		}
+
+		leading = true
+
+		return lease{Expires: now.Add(e.duration), Holder: e.id}, true
+	})
+
+	if err != nil {
+		leading = false
+	}
+
+	e.set(leading)
+
+	return leading, err
+}
+
+func (e *election) leader() bool {
+	return atomic.LoadInt32(&e.leading) == 1
+}
+
+// locked reads the lease and, if fn asks for it, replaces it while holding an
+// exclusive lock, so that two replicas never both think they've acquired it.
+func (e *election) locked(fn func(current lease) (lease, bool)) error {
+	if err := os.MkdirAll(e.path, 0755); err != nil {
+		return errors.WithMessage(err, "unable to create data path")
+	}
+
+	lock, err := os.OpenFile(filepath.Join(e.path, "leader.lock"), os.O_CREATE|os.O_RDWR, 0644)
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to open leader lock")
+	}
+
+	defer lock.Close()
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return errors.WithMessage(err, "unable to acquire leader lock")
+	}
+
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	var current lease
+
+	file := filepath.Join(e.path, "leader.json")
+	data, err := ioutil.ReadFile(file)
+
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithMessage(err, "unable to read lease")
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &current); err != nil {
+			return errors.WithMessage(err, "unable to decode lease")
+		}
+	}
+
+	next, ok := fn(current)
+
+	if !ok {
+		return nil
+	}
+
+	if data, err = json.Marshal(next); err != nil {
+		return errors.WithMessage(err, "unable to encode lease")
+	}
+
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return errors.WithMessage(err, "unable to write lease")
+	}
+
+	return nil
+}
+
+// resign gives up the lease, so that another replica can take over right away
+// instead of waiting for it to expire.
+func (e *election) resign() error {
+	e.set(false)
+
+	return e.locked(func(current lease) (lease, bool) {
+		if current.Holder != e.id {
+			return current, false
+		}
+
+		return lease{}, true
+	})
+}
+
+func (e *election) set(leading bool) {
+	var value int32
+
+	if leading {
+		value = 1
+	}
+
+	if atomic.SwapInt32(&e.leading, value) != value && e.onChange != nil {
+		e.onChange(leading)
+	}
+}
+
+func instanceID() string {
+	hostname, err := os.Hostname()
+
+	if err != nil {
+		hostname = "pingu"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// Leader reports whether this replica is the one that should act on events
+// and run tasks. It always is unless leader election has been enabled.
+func (p *Pingu) Leader() bool {
+	return p.election == nil || p.election.leader()
+}
+
+// campaign keeps campaigning for leadership until stopped. The first campaign
+// is expected to have been run by elect already.
+func (p *Pingu) campaign(stop <-chan struct{}) {
+	ticker := time.NewTicker(p.election.duration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.elect()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (p *Pingu) elect() {
+	if _, err := p.election.campaign(time.Now()); err != nil {
+		p.logger.Error(err)
+	}
+}
+
+func (p *Pingu) leadershipChanged(leading bool) {
+	p.logger.WithFields(logrus.Fields{
+		"instance": p.election.id,
+		"leader":   leading,
+	}).Info("Leadership changed")
+
+	if !leading || p.scheduler == nil {
+		return
+	}
+
+	// The previous leader may have changed the state of tasks since it was
+	// last loaded, so it's reloaded before anything is run.
+	p.scheduler.reload()
+
+	if err := p.aliases.reload(); err != nil {
+		p.logger.Error(err)
+	}
+
+	for _, ws := range p.workspaces {
+		if ws.online() {
+			p.scheduler.resume(ws, time.Now())
+		}
+	}
+
+	go p.reporter.notice(fmt.Sprintf("`%s` is now the leader.", p.election.id))
+}
@@ -0,0 +1,63 @@
+package pingu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestElection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pingu")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	changes := make([]bool, 0)
+	first := newElection(dir, "first", time.Minute, func(leading bool) {
+		changes = append(changes, leading)
+	})
+	second := newElection(dir, "second", time.Minute, nil)
+	now := time.Now()
+
+	steps := []struct {
+		name     string
+		election *election
+		now      time.Time
+		expected bool
+	}{
+		{"first acquires", first, now, true},
+		{"second is refused", second, now.Add(time.Second), false},
+		{"first renews", first, now.Add(30 * time.Second), true},
+		{"second is refused after renewal", second, now.Add(80 * time.Second), false},
+		{"second acquires after expiry", second, now.Add(2 * time.Minute), true},
+		{"first is refused", first, now.Add(2 * time.Minute), false},
+	}
+
+	for _, step := range steps {
+		actual, err := step.election.campaign(step.now)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != step.expected || step.election.leader() != step.expected {
+			t.Errorf("%s: campaign() was incorrect, got: %v, want %v.", step.name, actual, step.expected)
+		}
+	}
+
+	if err := second.resign(); err != nil {
+		t.Fatal(err)
+	}
+
+	if leading, _ := first.campaign(now.Add(2 * time.Minute)); !leading {
+		t.Errorf("campaign() was incorrect, the lease should be free after resigning.")
+	}
+
+	if expected := []bool{true, false, true}; len(changes) != len(expected) || changes[0] != expected[0] || changes[1] != expected[1] || changes[2] != expected[2] {
+		t.Errorf("onChange was called incorrectly, got: %v, want %v.", changes, expected)
+	}
+}
@@ -0,0 +1,103 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"os"
+)
+
+// NewLogger creates a logger from pingu.log.level, pingu.log.format (text or
+// json) and pingu.log.output (stderr, stdout or the path to a file).
+func NewLogger(config *viper.Viper) (*logrus.Logger, error) {
+	logger := logrus.New()
+
+	if level := config.GetString("pingu.log.level"); level != "" {
+		parsed, err := logrus.ParseLevel(level)
+
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid log level")
+		}
+
+		logger.SetLevel(parsed)
+	}
+
+	switch format := config.GetString("pingu.log.format"); format {
+	case "", "text":
+	case "json":
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return nil, errors.Errorf("unknown log format %q", format)
+	}
+
+	switch output := config.GetString("pingu.log.output"); output {
+	case "", "stderr":
+	case "stdout":
+		logger.SetOutput(os.Stdout)
+	default:
+		f, err := os.OpenFile(output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+		if err != nil {
+			return nil, errors.WithMessage(err, "unable to open log output")
+		}
+
+		logger.SetOutput(f)
+	}
+
+	return logger, nil
+}
+
+// newPluginLoggers creates a logger for every plugin whose level has been
+// overridden in pingu.log.plugins, sharing everything else with the logger.
+func newPluginLoggers(config *viper.Viper, logger *logrus.Logger) (map[string]*logrus.Logger, error) {
+	loggers := make(map[string]*logrus.Logger)
+
+	for name, level := range config.GetStringMapString("pingu.log.plugins") {
+		parsed, err := logrus.ParseLevel(level)
+
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid log level for "+name)
+		}
+
+		loggers[slug(name)] = &logrus.Logger{
+			ExitFunc:     logger.ExitFunc,
+			Formatter:    logger.Formatter,
+			Hooks:        logger.Hooks,
+			Level:        parsed,
+			Out:          logger.Out,
+			ReportCaller: logger.ReportCaller,
+		}
+	}
+
+	return loggers, nil
+}
+
+// Logger returns a logger for the plugin that the current command, task, event
+// or route belongs to, or the shared one outside of those.
+func (p *Pingu) Logger() *logrus.Entry {
+	if p.invocation == nil || p.invocation.plugin == nil {
+		return logrus.NewEntry(p.logger)
+	}
+
+	return p.pluginLogger(p.invocation.plugin)
+}
+
+func (p *Pingu) pluginLogger(plugin Plugin) *logrus.Entry {
+	logger := p.logger
+	id := p.pluginID(plugin)
+
+	if override, ok := p.pluginLoggers[id]; ok {
+		logger = override
+	}
+
+	entry := logger.WithFields(logrus.Fields{
+		"plugin":  plugin.Name(),
+		"version": plugin.Version(),
+	})
+
+	if instance := p.Instance(plugin); instance != "" {
+		entry = entry.WithField("instance", instance)
+	}
+
+	return entry
+}
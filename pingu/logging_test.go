@@ -0,0 +1,94 @@
+package pingu
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		settings  map[string]string
+		level     logrus.Level
+		json      bool
+		expectErr bool
+	}{
+		{"defaults", map[string]string{}, logrus.InfoLevel, false, false},
+		{"debug as json", map[string]string{"pingu.log.level": "debug", "pingu.log.format": "json"}, logrus.DebugLevel, true, false},
+		{"invalid level", map[string]string{"pingu.log.level": "loud"}, 0, false, true},
+		{"invalid format", map[string]string{"pingu.log.format": "xml"}, 0, false, true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := viper.New()
+
+			for key, value := range testCase.settings {
+				config.Set(key, value)
+			}
+
+			logger, err := NewLogger(config)
+
+			if testCase.expectErr {
+				if err == nil {
+					t.Errorf("NewLogger() should have failed.")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if logger.Level != testCase.level {
+				t.Errorf("NewLogger() level was incorrect, got: %s, want %s.", logger.Level, testCase.level)
+			}
+
+			if _, ok := logger.Formatter.(*logrus.JSONFormatter); ok != testCase.json {
+				t.Errorf("NewLogger() format was incorrect, got JSON: %v, want %v.", ok, testCase.json)
+			}
+		})
+	}
+}
+
+func TestPluginLogger(t *testing.T) {
+	t.Parallel()
+
+	config := viper.New()
+	config.Set("pingu.log.plugins", map[string]string{"aoc": "debug"})
+
+	logger := logrus.New()
+	loggers, err := newPluginLoggers(config, logger)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plugin := &taskPlugin{}
+	p := &Pingu{state: &state{
+		instances:     map[Plugin]*instance{plugin: {first: true, name: "aoc"}},
+		logger:        logger,
+		pluginLoggers: loggers,
+	}}
+	entry := p.scoped(&invocation{plugin: plugin}).Logger()
+
+	if entry.Logger.Level != logrus.DebugLevel {
+		t.Errorf("Logger() level was incorrect, got: %s, want %s.", entry.Logger.Level, logrus.DebugLevel)
+	}
+
+	if _, ok := entry.Data["instance"]; ok || entry.Data["plugin"] != "Advent of Code" {
+		t.Errorf("Logger() fields were incorrect, got: %v.", entry.Data)
+	}
+
+	if p.Logger().Logger != logger {
+		t.Errorf("Logger() should return the shared logger outside of plugins.")
+	}
+}
@@ -2,39 +2,97 @@ package pingu
 
 import (
 	"fmt"
-	"github.com/robfig/cron/v3"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/spf13/viper"
+	"os"
+	"os/signal"
 	"regexp"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
+type Accept int
+
+const (
+	AcceptBots Accept = 1 << iota
+	AcceptBroadcasts
+	AcceptEdits
+)
+
 type Command struct {
+	Accept      Accept
+	Admin       bool
+	Aliases     []string
+	Audit       bool
+	Channels    []string
+	Cooldown    Cooldown
 	Description string
-	Func        func(pi *Pingu, ev *slack.MessageEvent)
+	Func        func(pi *Pingu, ev *slack.MessageEvent) error
+	Response    ResponseMode
+	Slash       string
 	Trigger     *regexp.Regexp
 }
 
 type Commands []*Command
 
 type Pingu struct {
-	builtAt     time.Time
-	connectedAt time.Time
-	config      *viper.Viper
-	latency     time.Duration
-	logger      *logrus.Logger
-	name        string
-	plugins     Plugins
-	rtm         *slack.RTM
-	startedAt   time.Time
-	version     string
+	*state
+	invocation *invocation
+	workspace  *workspace
+}
+
+type ResponseMode int
+
+const (
+	ResponseChannel ResponseMode = iota
+	ResponseThread
+	ResponseEphemeral
+	ResponseDirect
+)
+
+type state struct {
+	aliases          *aliases
+	auditLog         *auditLog
+	backoff          *backoff
+	builtAt          time.Time
+	config           *viper.Viper
+	cooldowns        *cooldowns
+	election         *election
+	failures         map[string]int
+	failuresMu       sync.Mutex
+	flood            *flood
+	instances        map[Plugin]*instance
+	logger           *logrus.Logger
+	middlewares      Middlewares
+	name             string
+	pluginLoggers    map[string]*logrus.Logger
+	plugins          Plugins
+	queue            *queue
+	reporter         *reporter
+	responses        *responseCache
+	scheduler        *scheduler
+	snippetThreshold int
+	splitLength      int
+	storage          *storage
+	startedAt        time.Time
+	version          string
+	workspaces       []*workspace
 }
 
 type Task struct {
-	Func     func(pi *Pingu)
-	Interval time.Duration
-	Spec     string
+	CatchUp       bool
+	Func          func(pi *Pingu) error
+	Interval      time.Duration
+	Jitter        time.Duration
+	Location      *time.Location
+	Name          string
+	SkipIfRunning bool
+	Spec          string
+	Timeout       time.Duration
 }
 
 type Tasks []*Task
@@ -59,36 +117,143 @@ func New(config *viper.Viper, logger *logrus.Logger) *Pingu {
 		logger.Fatal(err)
 	}
 
-	plugins := make(Plugins, len(factories))
+	pluginLoggers, err := newPluginLoggers(config, logger)
 
-	for i, factory := range factories {
-		plugins[i] = factory(config)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	plugins, instances, err := newPlugins(config, factories, logger)
 
-		logger.WithFields(logrus.Fields{
-			"author":  plugins[i].Author(),
-			"name":    plugins[i].Name(),
-			"version": plugins[i].Version(),
-		}).Info("Plugin loaded")
+	if err != nil {
+		logger.Fatal(err)
 	}
 
-	api := slack.New(config.GetString("slack.token"))
-	rtm := api.NewRTM()
+	plugins = append(plugins, &adminPlugin{})
+
 	builtAtTime, err := time.Parse(time.RFC3339, builtAt)
 
 	if err != nil {
 		logger.Fatal(err)
 	}
 
-	return &Pingu{
-		builtAt:   builtAtTime,
-		config:    config,
-		logger:    logger,
-		name:      "Pingu",
-		plugins:   plugins,
-		rtm:       rtm,
-		startedAt: time.Now(),
-		version:   version,
+	floodIgnore := time.Minute
+	floodInterval := 10 * time.Second
+	floodMessages := 5
+	retries := 3
+	snippetThreshold := 12000
+	splitLength := config.GetInt("pingu.split_length")
+
+	if splitLength <= 0 {
+		splitLength = 4000
+	}
+
+	if config.IsSet("pingu.flood_ignore") {
+		floodIgnore = config.GetDuration("pingu.flood_ignore")
+	}
+
+	if config.IsSet("pingu.flood_interval") {
+		floodInterval = config.GetDuration("pingu.flood_interval")
+	}
+
+	if config.IsSet("pingu.flood_messages") {
+		floodMessages = config.GetInt("pingu.flood_messages")
+	}
+
+	if config.IsSet("pingu.snippet_threshold") {
+		snippetThreshold = config.GetInt("pingu.snippet_threshold")
+	}
+
+	if config.IsSet("pingu.queue_retries") {
+		retries = config.GetInt("pingu.queue_retries")
+	}
+
+	auditRetention := 90 * 24 * time.Hour
+	reconnectFactor := 2.0
+	reconnectJitter := 0.2
+	reconnectMax := 5 * time.Minute
+	reconnectMin := time.Second
+
+	if config.IsSet("pingu.audit_retention") {
+		auditRetention = config.GetDuration("pingu.audit_retention")
 	}
+
+	if config.IsSet("pingu.reconnect_factor") {
+		reconnectFactor = config.GetFloat64("pingu.reconnect_factor")
+	}
+
+	if config.IsSet("pingu.reconnect_jitter") {
+		reconnectJitter = config.GetFloat64("pingu.reconnect_jitter")
+	}
+
+	if config.IsSet("pingu.reconnect_max") {
+		reconnectMax = config.GetDuration("pingu.reconnect_max")
+	}
+
+	if config.IsSet("pingu.reconnect_min") {
+		reconnectMin = config.GetDuration("pingu.reconnect_min")
+	}
+
+	storage := newStorage(config.GetString("pingu.data_path"))
+
+	p := &Pingu{
+		state: &state{
+			aliases:       newAliases(config, plugins, storage),
+			auditLog:      newAuditLog(config.GetString("pingu.data_path"), auditRetention),
+			backoff:       newBackoff(reconnectMin, reconnectMax, reconnectFactor, reconnectJitter),
+			builtAt:       builtAtTime,
+			config:        config,
+			cooldowns:     newCooldowns(),
+			failures:      make(map[string]int),
+			flood:         newFlood(floodMessages, floodInterval, floodIgnore),
+			instances:     instances,
+			logger:        logger,
+			name:          "Pingu",
+			pluginLoggers: pluginLoggers,
+			plugins:       plugins,
+			queue: newQueue(
+				logger,
+				config.GetDuration("pingu.queue_interval"),
+				retries,
+				config.GetInt("pingu.queue_size"),
+			),
+			responses:        newResponseCache(1000),
+			snippetThreshold: snippetThreshold,
+			splitLength:      splitLength,
+			startedAt:        time.Now(),
+			storage:          storage,
+			version:          version,
+			workspaces:       newWorkspaces(config, storage),
+		},
+	}
+
+	if config.GetBool("pingu.leader_election") {
+		if config.GetString("pingu.data_path") == "" {
+			logger.Fatal("Leader election requires a data path")
+		}
+
+		id := config.GetString("pingu.instance_id")
+
+		if id == "" {
+			id = instanceID()
+		}
+
+		p.election = newElection(config.GetString("pingu.data_path"), id, config.GetDuration("pingu.lease_duration"), p.leadershipChanged)
+		storage.writable = p.Leader
+	}
+
+	if err := p.aliases.reload(); err != nil {
+		logger.Error(err)
+	}
+
+	p.reporter = newReporter(config.GetDuration("pingu.ops_interval"), p.postOps)
+	p.queue.onDrop = func(ch string, err error) {
+		if ch != p.opsChannel().Channel {
+			p.reporter.report("queue", errors.WithMessage(err, "message to <#"+ch+"> dropped"), time.Now())
+		}
+	}
+
+	return p
 }
 
 func (p *Pingu) BuiltAt() time.Time {
@@ -96,7 +261,29 @@ func (p *Pingu) BuiltAt() time.Time {
 }
 
 func (p *Pingu) ConnectedAt() time.Time {
-	return p.connectedAt
+	connectedAt, _ := p.current().times()
+
+	return connectedAt
+}
+
+func (p *Pingu) Delete(ref MessageRef) error {
+	return p.queue.send(ref.Channel, func() error {
+		_, _, err := p.workspaceFor(ref).client.DeleteMessage(ref.Channel, ref.Timestamp)
+
+		return err
+	})
+}
+
+func (p *Pingu) Directory() *Directory {
+	return p.current().directory
+}
+
+func (p *Pingu) Edit(ref MessageRef, msg RichMessage) error {
+	return p.queue.send(ref.Channel, func() error {
+		_, _, _, err := p.workspaceFor(ref).client.UpdateMessage(ref.Channel, ref.Timestamp, msg.options()...)
+
+		return err
+	})
 }
 
 func (p *Pingu) FriendlyVersion() string {
@@ -115,23 +302,73 @@ func (p *Pingu) FriendlyVersion() string {
 }
 
 func (p *Pingu) Latency() time.Duration {
-	return p.latency
-}
-
-func (p *Pingu) Logger() *logrus.Logger {
-	return p.logger
+	return p.current().currentLatency()
 }
 
 func (p *Pingu) Name() string {
 	return p.name
 }
 
+func (p *Pingu) OpenDirect(user string) (string, error) {
+	ws := p.current()
+
+	ws.directsMu.Lock()
+	defer ws.directsMu.Unlock()
+
+	if ch, ok := ws.directs[user]; ok {
+		return ch, nil
+	}
+
+	ch, _, _, err := ws.client.OpenConversation(&slack.OpenConversationParameters{
+		Users: []string{user},
+	})
+
+	if err != nil {
+		p.logger.Error(err)
+		return "", err
+	}
+
+	ws.directs[user] = ch.ID
+
+	return ch.ID, nil
+}
+
 func (p *Pingu) Plugins() Plugins {
 	return p.plugins
 }
 
-func (p *Pingu) Reply(ev *slack.MessageEvent, msg string) {
-	p.Say(fmt.Sprintf("<@%s>: %s", ev.User, msg), ev.Channel)
+func (p *Pingu) React(ref MessageRef, emoji string) error {
+	return p.queue.send(ref.Channel, func() error {
+		return p.workspaceFor(ref).client.AddReaction(strings.Trim(emoji, ":"), slack.NewRefToMessage(ref.Channel, ref.Timestamp))
+	})
+}
+
+func (p *Pingu) Reply(ev *slack.MessageEvent, msg string) (MessageRef, error) {
+	mode := p.responseMode(ev)
+
+	if mode == ResponseChannel || mode == ResponseThread {
+		msg = fmt.Sprintf("<@%s>: %s", ev.User, msg)
+	}
+
+	return p.respond(ev, mode, Text(msg))
+}
+
+func (p *Pingu) ReplyEphemeral(ev *slack.MessageEvent, msg string) (MessageRef, error) {
+	return p.respond(ev, ResponseEphemeral, Text(msg))
+}
+
+func (p *Pingu) ReplyInThread(ref MessageRef, msg string) (MessageRef, error) {
+	thread := ref.Thread
+
+	if thread == "" {
+		thread = ref.Timestamp
+	}
+
+	return p.postMessage(MessageRef{Channel: ref.Channel, Thread: thread}, Text(msg))
+}
+
+func (p *Pingu) Respond(ev *slack.MessageEvent, msg RichMessage) (MessageRef, error) {
+	return p.respond(ev, p.responseMode(ev), msg)
 }
 
 func (p *Pingu) Run() {
@@ -140,102 +377,95 @@ func (p *Pingu) Run() {
 		"version": p.version,
 	}).Info("Pingu started")
 
-	c := cron.New()
-	w := p.logger.Writer()
+	s, err := newScheduler(p)
 
-	defer w.Close()
+	if err != nil {
+		p.logger.Fatal(err)
+	}
 
-	for _, plugin := range p.plugins {
-		plugin := plugin
-		for _, task := range plugin.Tasks() {
-			task := task
-			spec := task.Spec
+	p.scheduler = s
 
-			if spec == "" {
-				spec = fmt.Sprintf("@every %s", task.Interval.String())
-			}
+	events := make(chan workspaceEvent)
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
 
-			if _, err := c.AddFunc(spec, func() {
-				task.Func(p)
-				p.logger.WithFields(logrus.Fields{
-					"plugin": plugin.Name(),
-				}).Info("Task executed")
-			}); err != nil {
-				p.logger.Fatal(err)
-			}
-		}
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	// Leadership is settled before connecting, as only the leader tells the
+	// ops channel about connections.
+	if p.election != nil {
+		p.elect()
+		go p.campaign(stop)
 	}
 
-	go p.rtm.ManageConnection()
+	go p.reporter.run(stop)
+	go p.serve()
 
-	for msg := range p.rtm.IncomingEvents {
-		switch ev := msg.Data.(type) {
-		case *slack.ConnectedEvent:
-			p.connectedAt = time.Now()
-			p.logger.Info("Connection established")
+	for _, ws := range p.workspaces {
+		go p.connect(ws, events, stop)
+	}
 
-			for _, plugin := range p.plugins {
-				plugin := plugin
-				for _, task := range plugin.Tasks() {
-					// Specs are meant to run at specific times.
-					if task.Spec != "" {
-						continue
-					}
+	for {
+		select {
+		case event := <-events:
+			p.on(event.workspace).receive(event.msg)
+		case sig := <-signals:
+			p.logger.WithField("signal", sig).Info("Pingu stopping")
+			s.stop()
+			close(stop)
+			p.reporter.flush(time.Now(), true)
+
+			if p.Leader() {
+				p.reporter.notice(fmt.Sprintf("%s is shutting down.", p.name))
+			}
 
-					task := task
-					task.Func(p)
-					p.logger.WithFields(logrus.Fields{
-						"plugin": plugin.Name(),
-					}).Info("Task executed")
+			for _, ws := range p.workspaces {
+				if err := ws.connections.disconnected(time.Now(), "shutdown"); err != nil {
+					p.logger.Error(err)
 				}
 			}
 
-			c.Start()
-		case *slack.DisconnectedEvent:
-			p.logger.Info("Connection lost")
-			c.Stop()
-		case *slack.LatencyReport:
-			p.latency = ev.Value
-		case *slack.InvalidAuthEvent:
-			p.logger.Fatal("Authentication failed")
-		case *slack.MessageEvent:
-			for _, plugin := range p.plugins {
-				plugin := plugin
-				for _, command := range plugin.Commands() {
-					command := command
-					if command.Trigger.MatchString(ev.Text) {
-						p.logger.WithFields(logrus.Fields{
-							"plugin":  plugin.Name(),
-							"trigger": command.Trigger.String(),
-						}).Info("Command triggered")
-						command.Func(p, ev)
-					}
+			if p.election != nil {
+				if err := p.election.resign(); err != nil {
+					p.logger.Error(err)
 				}
 			}
+
+			return
 		}
 	}
 }
 
-func (p *Pingu) Say(msg string, ch string) {
-	p.rtm.SendMessage(p.rtm.NewOutgoingMessage(msg, ch))
+func (p *Pingu) Say(msg string, ch string) (MessageRef, error) {
+	return p.postMessage(p.target(ch), Text(msg))
 }
 
-func (p *Pingu) SendAttachments(attachments []slack.Attachment, msg string, ch string) {
-	params := slack.NewPostMessageParameters()
+func (p *Pingu) SayDirect(msg string, user string) (MessageRef, error) {
+	ch, err := p.OpenDirect(user)
 
-	params.LinkNames = 1
+	if err != nil {
+		return MessageRef{}, err
+	}
 
-	_, _, err := p.rtm.PostMessage(
-		ch,
-		slack.MsgOptionPostMessageParameters(params),
-		slack.MsgOptionAsUser(true),
-		slack.MsgOptionDisableLinkUnfurl(),
-		slack.MsgOptionAttachments(attachments...),
-	)
+	return p.postMessage(MessageRef{Channel: ch}, Text(msg))
+}
+
+func (p *Pingu) Send(ch string, msg RichMessage) (MessageRef, error) {
+	return p.postMessage(p.target(ch), msg)
+}
+
+func (p *Pingu) SendAttachments(attachments []slack.Attachment, msg string, ch string) (MessageRef, error) {
+	return p.postMessage(p.target(ch), Text(msg), slack.MsgOptionAttachments(attachments...))
+}
+
+func (p *Pingu) SendDirect(user string, msg RichMessage) (MessageRef, error) {
+	ch, err := p.OpenDirect(user)
 
 	if err != nil {
-		p.logger.Error(err)
+		return MessageRef{}, err
 	}
+
+	return p.postMessage(MessageRef{Channel: ch}, msg)
 }
 
 func (p *Pingu) StartedAt() time.Time {
@@ -245,3 +475,316 @@ func (p *Pingu) StartedAt() time.Time {
 func (p *Pingu) Version() string {
 	return p.version
 }
+
+func (p *Pingu) post(ref MessageRef, options ...slack.MsgOption) (MessageRef, error) {
+	options = append(postOptions(), options...)
+	ws := p.workspaceFor(ref)
+
+	if previous, ok := p.invocation.reuse(ref.Channel); ok {
+		err := p.queue.send(previous.Channel, func() error {
+			_, _, _, err := ws.client.UpdateMessage(previous.Channel, previous.Timestamp, options...)
+
+			return err
+		})
+
+		p.invocation.record(previous)
+
+		return previous, err
+	}
+
+	if ref.Thread != "" {
+		options = append(options, slack.MsgOptionTS(ref.Thread))
+	}
+
+	sent := MessageRef{Channel: ref.Channel, Thread: ref.Thread, Workspace: ws.name}
+
+	err := p.queue.send(ref.Channel, func() error {
+		channel, ts, err := ws.client.PostMessage(ref.Channel, options...)
+
+		if err == nil {
+			sent.Channel = channel
+			sent.Timestamp = ts
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return sent, err
+	}
+
+	p.invocation.record(sent)
+
+	return sent, nil
+}
+
+func (p *Pingu) postEphemeral(ref MessageRef, user string, options ...slack.MsgOption) (MessageRef, error) {
+	options = append(postOptions(), options...)
+
+	if ref.Thread != "" {
+		options = append(options, slack.MsgOptionTS(ref.Thread))
+	}
+
+	ws := p.workspaceFor(ref)
+	sent := MessageRef{Channel: ref.Channel, Thread: ref.Thread, Workspace: ws.name}
+
+	err := p.queue.send(ref.Channel, func() error {
+		ts, err := ws.client.PostEphemeral(ref.Channel, user, options...)
+		sent.Timestamp = ts
+
+		return err
+	})
+
+	return sent, err
+}
+
+// postMessage posts msg to the referenced channel, either split into multiple
+// messages or as a snippet if it is too long to fit into a single message.
+func (p *Pingu) postMessage(ref MessageRef, msg RichMessage, options ...slack.MsgOption) (MessageRef, error) {
+	if len(msg.Blocks) == 0 && len(options) == 0 && p.snippetThreshold > 0 && len(msg.Text) > p.snippetThreshold {
+		sent, err := p.upload(ref, msg.Text)
+
+		if err == nil {
+			return sent, nil
+		}
+
+		p.logger.WithField("error", err).Warn("Unable to upload snippet, splitting message instead")
+	}
+
+	return p.sendSplit(msg, func(parts ...slack.MsgOption) (MessageRef, error) {
+		return p.post(ref, parts...)
+	}, options...)
+}
+
+func (p *Pingu) postResponse(ev *slack.MessageEvent, mode ResponseMode, options ...slack.MsgOption) (MessageRef, error) {
+	responseType := slack.ResponseTypeInChannel
+
+	if mode == ResponseEphemeral {
+		responseType = slack.ResponseTypeEphemeral
+	}
+
+	options = append(options, slack.MsgOptionResponseURL(p.invocation.responseURL, responseType))
+
+	err := p.queue.send(ev.Channel, func() error {
+		_, _, _, err := p.current().client.SendMessage(ev.Channel, options...)
+
+		return err
+	})
+
+	return MessageRef{Channel: ev.Channel, Workspace: p.current().name}, err
+}
+
+func (p *Pingu) receive(msg slack.RTMEvent) {
+	ws := p.current()
+
+	switch ev := msg.Data.(type) {
+	case *slack.ConnectedEvent:
+		now := time.Now()
+		previous := ws.markConnected(now, ev.Info.User.ID, ev.Info.Team.ID)
+		p.logger.WithField("workspace", ws.name).Info("Connection established")
+
+		if err := ws.connections.connected(now, previous.IsZero()); err != nil {
+			p.logger.Error(err)
+		}
+
+		go func() {
+			if err := ws.directory.refresh(ws.client); err != nil {
+				p.logger.Error(err)
+			}
+		}()
+
+		if notice := p.connectedNotice(ws, previous); notice != "" && p.Leader() {
+			go p.reporter.notice(notice)
+		}
+
+		if p.Leader() {
+			p.scheduler.resume(ws, now)
+		}
+	case *slack.DisconnectedEvent:
+		now := time.Now()
+		ws.markDisconnected(now)
+		p.logger.WithFields(logrus.Fields{
+			"cause":     ev.Cause,
+			"workspace": ws.name,
+		}).Info("Connection lost")
+
+		reason := "unknown"
+
+		if ev.Cause != nil {
+			reason = ev.Cause.Error()
+		}
+
+		if err := ws.connections.disconnected(now, reason); err != nil {
+			p.logger.Error(err)
+		}
+	case *slack.LatencyReport:
+		ws.setLatency(ev.Value)
+	case *slack.InvalidAuthEvent:
+		p.logger.WithField("workspace", ws.name).Fatal("Authentication failed")
+	case *slack.MessageEvent:
+		// Every replica stays connected so that it can take over right away,
+		// but only the leader acts on what it receives.
+		if p.Leader() {
+			p.dispatch(ev)
+		}
+	default:
+		ws.directory.update(msg.Data)
+
+		if p.Leader() {
+			p.dispatchEvent(msg)
+		}
+	}
+}
+
+// connectedNotice returns what to tell the ops channel about a connection to
+// the workspace, given when it was previously connected.
+func (p *Pingu) connectedNotice(ws *workspace, previous time.Time) string {
+	name := ""
+	connectedAt, disconnectedAt := ws.times()
+
+	if len(p.workspaces) > 1 {
+		name = " " + ws.name
+	}
+
+	switch {
+	case previous.IsZero() && ws == p.workspaces[0]:
+		return fmt.Sprintf("%s %s is up and running.", p.name, p.FriendlyVersion())
+	case previous.IsZero():
+		return fmt.Sprintf("Connected to%s.", name)
+	case !disconnectedAt.IsZero():
+		return fmt.Sprintf("Reconnected%s after being disconnected for %s.", name, downtime(connectedAt.Sub(disconnectedAt)))
+	default:
+		return ""
+	}
+}
+
+func (p *Pingu) respond(ev *slack.MessageEvent, mode ResponseMode, msg RichMessage) (MessageRef, error) {
+	if p.invocation != nil && p.invocation.responseURL != "" && p.invocation.event == ev && mode != ResponseDirect {
+		return p.sendSplit(msg, func(options ...slack.MsgOption) (MessageRef, error) {
+			return p.postResponse(ev, mode, options...)
+		})
+	}
+
+	switch mode {
+	case ResponseThread:
+		thread := ev.ThreadTimestamp
+
+		if thread == "" {
+			thread = ev.Timestamp
+		}
+
+		return p.postMessage(MessageRef{Channel: ev.Channel, Thread: thread}, msg)
+	case ResponseEphemeral:
+		return p.sendSplit(msg, func(options ...slack.MsgOption) (MessageRef, error) {
+			return p.postEphemeral(MessageRef{Channel: ev.Channel, Thread: ev.ThreadTimestamp}, ev.User, options...)
+		})
+	case ResponseDirect:
+		ch, err := p.OpenDirect(ev.User)
+
+		if err != nil {
+			return MessageRef{}, err
+		}
+
+		return p.postMessage(MessageRef{Channel: ch}, msg)
+	default:
+		return p.postMessage(MessageRef{Channel: ev.Channel, Thread: ev.ThreadTimestamp}, msg)
+	}
+}
+
+// Commands declare how they prefer to be responded to, but that preference
+// only applies to the message that actually invoked them.
+func (p *Pingu) responseMode(ev *slack.MessageEvent) ResponseMode {
+	if p.invocation == nil || p.invocation.command == nil || p.invocation.event != ev {
+		return ResponseChannel
+	}
+
+	return p.invocation.command.Response
+}
+
+// sendSplit sends msg in as many parts as it takes to stay within Slack's
+// limits, and returns the reference to the first part. Any additional options
+// are only applied to the last part.
+func (p *Pingu) sendSplit(msg RichMessage, send func(options ...slack.MsgOption) (MessageRef, error), options ...slack.MsgOption) (MessageRef, error) {
+	parts := msg.split(p.splitLength)
+
+	var first MessageRef
+
+	for i, part := range parts {
+		partOptions := part.options()
+
+		if i == len(parts)-1 {
+			partOptions = append(partOptions, options...)
+		}
+
+		sent, err := send(partOptions...)
+
+		if i == 0 {
+			first = sent
+		}
+
+		if err != nil {
+			return first, err
+		}
+	}
+
+	return first, nil
+}
+
+func (p *Pingu) scoped(inv *invocation) *Pingu {
+	return &Pingu{
+		state:      p.state,
+		invocation: inv,
+		workspace:  p.workspace,
+	}
+}
+
+func (p *Pingu) upload(ref MessageRef, text string) (MessageRef, error) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	// The snippet itself is already rendered as code.
+	if len(lines) > 1 && lines[0] == codeFence && lines[len(lines)-1] == codeFence {
+		text = strings.Join(lines[1:len(lines)-1], "\n")
+	}
+
+	ws := p.workspaceFor(ref)
+
+	err := p.queue.send(ref.Channel, func() error {
+		_, err := ws.client.UploadFile(slack.FileUploadParameters{
+			Channels:        []string{ref.Channel},
+			Content:         text,
+			Filename:        "message.txt",
+			Filetype:        "text",
+			ThreadTimestamp: ref.Thread,
+		})
+
+		return err
+	})
+
+	return MessageRef{Channel: ref.Channel, Thread: ref.Thread, Workspace: ws.name}, err
+}
+
+// Messages sent to the channel a command was invoked in are posted to the
+// same thread as the invocation, if any.
+func (p *Pingu) target(ch string) MessageRef {
+	ref := p.resolve(ch)
+
+	if p.invocation == nil || p.invocation.event == nil || p.invocation.event.Channel != ref.Channel || ref.Workspace != p.current().name {
+		return ref
+	}
+
+	ref.Thread = p.invocation.event.ThreadTimestamp
+
+	return ref
+}
+
+func postOptions() []slack.MsgOption {
+	params := slack.NewPostMessageParameters()
+
+	params.LinkNames = 1
+
+	return []slack.MsgOption{
+		slack.MsgOptionPostMessageParameters(params),
+		slack.MsgOptionAsUser(true),
+		slack.MsgOptionDisableLinkUnfurl(),
+	}
+}
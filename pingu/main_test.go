@@ -1 +1,80 @@
 package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"reflect"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	threaded := &slack.MessageEvent{Msg: slack.Msg{Channel: "C012345", ThreadTimestamp: "1600000000.000100"}}
+	unthreaded := &slack.MessageEvent{Msg: slack.Msg{Channel: "C012345"}}
+
+	testCases := []struct {
+		name     string
+		event    *slack.MessageEvent
+		channel  string
+		expected MessageRef
+	}{
+		{"no event", nil, "C012345", MessageRef{Channel: "C012345", Workspace: defaultWorkspace}},
+		{"unthreaded", unthreaded, "C012345", MessageRef{Channel: "C012345", Workspace: defaultWorkspace}},
+		{"threaded", threaded, "C012345", MessageRef{Channel: "C012345", Thread: "1600000000.000100", Workspace: defaultWorkspace}},
+		{"other channel", threaded, "C678901", MessageRef{Channel: "C678901", Workspace: defaultWorkspace}},
+		{"other workspace", threaded, "work:C012345", MessageRef{Channel: "C012345", Workspace: "work"}},
+		{"qualified", threaded, "default:C012345", MessageRef{Channel: "C012345", Thread: "1600000000.000100", Workspace: defaultWorkspace}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var inv *invocation
+
+			if testCase.event != nil {
+				inv = &invocation{event: testCase.event}
+			}
+
+			p := (&Pingu{state: &state{workspaces: []*workspace{
+				{directory: newDirectory(), name: defaultWorkspace},
+				{directory: newDirectory(), name: "work"},
+			}}}).scoped(inv)
+
+			if actual := p.target(testCase.channel); !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("target() was incorrect, got: %+v, want %+v.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestResponseMode(t *testing.T) {
+	command := &Command{Response: ResponseEphemeral}
+	event := &slack.MessageEvent{}
+	other := &slack.MessageEvent{}
+
+	testCases := []struct {
+		name     string
+		command  *Command
+		event    *slack.MessageEvent
+		expected ResponseMode
+	}{
+		{"no command", nil, event, ResponseChannel},
+		{"invocation", command, event, ResponseEphemeral},
+		{"other event", command, other, ResponseChannel},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := (&Pingu{state: &state{}}).scoped(&invocation{command: testCase.command, event: event})
+
+			if actual := p.responseMode(testCase.event); testCase.expected != actual {
+				t.Errorf("responseMode() was incorrect, got: %v, want %v.", actual, testCase.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"strings"
+)
+
+type Block interface {
+	plainText() string
+	slackBlock() slack.Block
+}
+
+type ButtonStyle string
+
+const (
+	ButtonDefault ButtonStyle = ""
+	ButtonDanger  ButtonStyle = "danger"
+	ButtonPrimary ButtonStyle = "primary"
+)
+
+type Actions struct {
+	Buttons []Button
+	ID      string
+}
+
+type Button struct {
+	ActionID string
+	Style    ButtonStyle
+	Text     string
+	URL      string
+	Value    string
+}
+
+type Context struct {
+	Elements []string
+}
+
+type Divider struct{}
+
+type Header struct {
+	Text string
+}
+
+type Image struct {
+	AltText string
+	Title   string
+	URL     string
+}
+
+type MessageRef struct {
+	Channel   string
+	Thread    string
+	Timestamp string
+	Workspace string
+}
+
+type RichMessage struct {
+	Blocks []Block
+	Text   string
+}
+
+type Section struct {
+	Accessory *Button
+	Fields    []string
+	ID        string
+	Text      string
+}
+
+func NewMessageRef(ev *slack.MessageEvent) MessageRef {
+	return MessageRef{
+		Channel:   ev.Channel,
+		Thread:    ev.ThreadTimestamp,
+		Timestamp: ev.Timestamp,
+	}
+}
+
+func Text(text string) RichMessage {
+	return RichMessage{Text: text}
+}
+
+func (a Actions) plainText() string {
+	buttons := make([]string, len(a.Buttons))
+
+	for i, b := range a.Buttons {
+		buttons[i] = b.plainText()
+	}
+
+	return strings.Join(buttons, " ")
+}
+
+func (a Actions) slackBlock() slack.Block {
+	elements := make([]slack.BlockElement, len(a.Buttons))
+
+	for i, b := range a.Buttons {
+		elements[i] = b.slackElement()
+	}
+
+	return slack.NewActionBlock(a.ID, elements...)
+}
+
+func (b Button) plainText() string {
+	if b.URL != "" {
+		return "[" + b.Text + "](" + b.URL + ")"
+	}
+
+	return "[" + b.Text + "]"
+}
+
+func (b Button) slackElement() *slack.ButtonBlockElement {
+	element := slack.NewButtonBlockElement(
+		b.ActionID,
+		b.Value,
+		slack.NewTextBlockObject(slack.PlainTextType, b.Text, true, false),
+	)
+
+	element.URL = b.URL
+
+	return element.WithStyle(slack.Style(b.Style))
+}
+
+func (c Context) plainText() string {
+	return strings.Join(c.Elements, " | ")
+}
+
+func (c Context) slackBlock() slack.Block {
+	elements := make([]slack.MixedElement, len(c.Elements))
+
+	for i, e := range c.Elements {
+		elements[i] = slack.NewTextBlockObject(slack.MarkdownType, e, false, false)
+	}
+
+	return slack.NewContextBlock("", elements...)
+}
+
+func (d Divider) plainText() string {
+	return "---"
+}
+
+func (d Divider) slackBlock() slack.Block {
+	return slack.NewDividerBlock()
+}
+
+func (h Header) plainText() string {
+	return h.Text
+}
+
+func (h Header) slackBlock() slack.Block {
+	return slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, h.Text, true, false))
+}
+
+func (i Image) plainText() string {
+	if i.Title != "" {
+		return i.Title + ": " + i.URL
+	}
+
+	return i.URL
+}
+
+func (i Image) slackBlock() slack.Block {
+	var title *slack.TextBlockObject
+
+	if i.Title != "" {
+		title = slack.NewTextBlockObject(slack.PlainTextType, i.Title, true, false)
+	}
+
+	altText := i.AltText
+
+	if altText == "" {
+		altText = i.Title
+	}
+
+	return slack.NewImageBlock(i.URL, altText, "", title)
+}
+
+func (m RichMessage) PlainText() string {
+	if len(m.Blocks) == 0 {
+		return m.Text
+	}
+
+	lines := make([]string, 0, len(m.Blocks))
+
+	for _, b := range m.Blocks {
+		if text := b.plainText(); text != "" {
+			lines = append(lines, text)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m RichMessage) SlackBlocks() []slack.Block {
+	blocks := make([]slack.Block, len(m.Blocks))
+
+	for i, b := range m.Blocks {
+		blocks[i] = b.slackBlock()
+	}
+
+	return blocks
+}
+
+func (m RichMessage) fallback() string {
+	if m.Text != "" {
+		return m.Text
+	}
+
+	return m.PlainText()
+}
+
+func (m RichMessage) options() []slack.MsgOption {
+	options := []slack.MsgOption{
+		slack.MsgOptionText(m.fallback(), false),
+	}
+
+	if len(m.Blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(m.SlackBlocks()...))
+	}
+
+	return options
+}
+
+func (s Section) plainText() string {
+	lines := make([]string, 0, len(s.Fields)+2)
+
+	if s.Text != "" {
+		lines = append(lines, s.Text)
+	}
+
+	lines = append(lines, s.Fields...)
+
+	if s.Accessory != nil {
+		lines = append(lines, s.Accessory.plainText())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (s Section) slackBlock() slack.Block {
+	var accessory *slack.Accessory
+	var text *slack.TextBlockObject
+
+	if s.Accessory != nil {
+		accessory = slack.NewAccessory(s.Accessory.slackElement())
+	}
+
+	if s.Text != "" {
+		text = slack.NewTextBlockObject(slack.MarkdownType, s.Text, false, false)
+	}
+
+	fields := make([]*slack.TextBlockObject, len(s.Fields))
+
+	for i, f := range s.Fields {
+		fields[i] = slack.NewTextBlockObject(slack.MarkdownType, f, false, false)
+	}
+
+	return slack.NewSectionBlock(text, fields, accessory, slack.SectionBlockOptionBlockID(s.ID))
+}
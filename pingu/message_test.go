@@ -0,0 +1,65 @@
+package pingu
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRichMessagePlainText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msg      RichMessage
+		expected string
+	}{
+		{"text", Text("Noot! Noot!"), "Noot! Noot!"},
+		{"blocks", RichMessage{
+			Blocks: []Block{
+				Header{Text: "Leaderboard"},
+				Section{Text: "*1.* _Pingu_", Fields: []string{"50 stars"}},
+				Divider{},
+				Context{Elements: []string{"a", "b"}},
+				Image{Title: "Pingu", URL: "https://example.com/pingu.png"},
+				Actions{Buttons: []Button{{Text: "Yes"}, {Text: "Docs", URL: "https://example.com"}}},
+			},
+		}, "Leaderboard\n*1.* _Pingu_\n50 stars\n---\na | b\nPingu: https://example.com/pingu.png\n[Yes] [Docs](https://example.com)"},
+		{"blocks ignore text", RichMessage{Blocks: []Block{Section{Text: "body"}}, Text: "fallback"}, "body"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := testCase.msg.PlainText(); testCase.expected != actual {
+				t.Errorf("PlainText() was incorrect, got: %q, want %q.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestRichMessageSlackBlocks(t *testing.T) {
+	msg := RichMessage{
+		Blocks: []Block{
+			Section{ID: "poll", Text: "Lunch?", Accessory: &Button{ActionID: "yes", Style: ButtonPrimary, Text: "Yes", Value: "1"}},
+			Divider{},
+			Actions{ID: "poll", Buttons: []Button{{ActionID: "no", Style: ButtonDanger, Text: "No", Value: "0"}}},
+		},
+	}
+
+	actual, err := json.Marshal(msg.SlackBlocks())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"type":"section","text":{"type":"mrkdwn","text":"Lunch?"},"block_id":"poll","accessory":{"type":"button","text":{"type":"plain_text","text":"Yes","emoji":true},"action_id":"yes","value":"1","style":"primary"}},` +
+		`{"type":"divider"},` +
+		`{"type":"actions","block_id":"poll","elements":[{"type":"button","text":{"type":"plain_text","text":"No","emoji":true},"action_id":"no","value":"0","style":"danger"}]}` +
+		`]`
+
+	if string(actual) != expected {
+		t.Errorf("SlackBlocks() was incorrect, got: %s, want %s.", actual, expected)
+	}
+}
@@ -0,0 +1,59 @@
+package pingu
+
+import (
+	"context"
+	"github.com/slack-go/slack"
+)
+
+type CommandHandler func(pi *Pingu, inv *Invocation) error
+
+type Invocation struct {
+	Command *Command
+	Context context.Context
+	Event   *slack.MessageEvent
+	Plugin  Plugin
+}
+
+type Middleware func(next CommandHandler) CommandHandler
+
+type Middlewares []Middleware
+
+type MiddlewareProvider interface {
+	Middlewares() Middlewares
+}
+
+func (p *Pingu) Context() context.Context {
+	if p.invocation == nil || p.invocation.ctx == nil {
+		return context.Background()
+	}
+
+	return p.invocation.ctx
+}
+
+func (p *Pingu) Use(middlewares ...Middleware) {
+	p.middlewares = append(p.middlewares, middlewares...)
+}
+
+// handler wraps the command in the plugin's middlewares, and those in the
+// global ones, so that global middlewares are the first to see an invocation.
+func (p *Pingu) handler(plugin Plugin) CommandHandler {
+	handler := CommandHandler(func(pi *Pingu, inv *Invocation) error {
+		pi.invocation.ctx = inv.Context
+
+		return inv.Command.Func(pi, inv.Event)
+	})
+
+	if provider, ok := plugin.(MiddlewareProvider); ok {
+		handler = chain(handler, provider.Middlewares())
+	}
+
+	return chain(handler, p.middlewares)
+}
+
+func chain(handler CommandHandler, middlewares Middlewares) CommandHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,74 @@
+package pingu
+
+import (
+	"context"
+	"github.com/slack-go/slack"
+	"reflect"
+	"testing"
+)
+
+type contextKey string
+
+type middlewarePlugin struct {
+	Plugin
+	calls *[]string
+}
+
+func (pl *middlewarePlugin) Middlewares() Middlewares {
+	return Middlewares{recordingMiddleware("plugin", pl.calls)}
+}
+
+func (pl *middlewarePlugin) Name() string {
+	return "Middleware"
+}
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(pi *Pingu, inv *Invocation) error {
+			*calls = append(*calls, name)
+			inv.Context = context.WithValue(inv.Context, contextKey(name), true)
+
+			return next(pi, inv)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	calls := make([]string, 0)
+	plugin := &middlewarePlugin{calls: &calls}
+	p := &Pingu{state: &state{}}
+
+	p.Use(recordingMiddleware("first", &calls), recordingMiddleware("second", &calls))
+
+	var ctx context.Context
+
+	command := &Command{Func: func(pi *Pingu, ev *slack.MessageEvent) error {
+		calls = append(calls, "command")
+		ctx = pi.Context()
+
+		return nil
+	}}
+
+	inv := &invocation{command: command, event: &slack.MessageEvent{}, plugin: plugin}
+
+	err := p.handler(plugin)(p.scoped(inv), &Invocation{
+		Command: command,
+		Context: context.Background(),
+		Event:   inv.event,
+		Plugin:  plugin,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"first", "second", "plugin", "command"}; !reflect.DeepEqual(expected, calls) {
+		t.Errorf("handler() was incorrect, got: %v, want %v.", calls, expected)
+	}
+
+	for _, key := range []string{"first", "second", "plugin"} {
+		if ctx == nil || ctx.Value(contextKey(key)) != true {
+			t.Errorf("handler() did not pass the context set by %q on to the command.", key)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package pingu
+
+import (
+	"fmt"
+	"github.com/hako/durafmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type incident struct {
+	count  int
+	err    string
+	source string
+}
+
+// reporter posts errors to the ops channel. Repeated errors are only posted
+// once per interval, with a count of how many times they happened since.
+// Posting only ever happens from run, as errors are reported from anywhere,
+// including the queue worker the post would have to go through.
+type reporter struct {
+	interval  time.Duration
+	incidents map[string]*incident
+	mu        sync.Mutex
+	pending   chan struct{}
+	reported  map[string]time.Time
+	send      func(text string)
+}
+
+func newReporter(interval time.Duration, send func(text string)) *reporter {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	return &reporter{
+		incidents: make(map[string]*incident),
+		interval:  interval,
+		pending:   make(chan struct{}, 1),
+		reported:  make(map[string]time.Time),
+		send:      send,
+	}
+}
+
+// flush posts a summary of every incident that hasn't been reported within
+// the interval. Passing force posts all of them regardless.
+func (r *reporter) flush(now time.Time, force bool) {
+	r.mu.Lock()
+
+	keys := make([]string, 0, len(r.incidents))
+
+	for key := range r.incidents {
+		if force || now.Sub(r.reported[key]) >= r.interval {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+
+	for i, key := range keys {
+		lines[i] = r.incidents[key].summary()
+		r.reported[key] = now
+		delete(r.incidents, key)
+	}
+
+	for key, reported := range r.reported {
+		if _, ok := r.incidents[key]; !ok && now.Sub(reported) >= r.interval {
+			delete(r.reported, key)
+		}
+	}
+
+	r.mu.Unlock()
+
+	if len(lines) > 0 {
+		r.send(strings.Join(lines, "\n"))
+	}
+}
+
+func (r *reporter) notice(text string) {
+	r.send("Noot! Noot! " + text)
+}
+
+// report records the error and, unless it has been reported before, has run
+// post it right away.
+func (r *reporter) report(source string, err error, now time.Time) {
+	// Only the first line, as panics carry their entire stack trace.
+	message := strings.SplitN(err.Error(), "\n", 2)[0]
+	key := source + "\x00" + message
+
+	r.mu.Lock()
+
+	if _, ok := r.incidents[key]; !ok {
+		r.incidents[key] = &incident{err: message, source: source}
+	}
+
+	r.incidents[key].count++
+
+	_, seen := r.reported[key]
+
+	r.mu.Unlock()
+
+	if seen {
+		return
+	}
+
+	select {
+	case r.pending <- struct{}{}:
+	default:
+	}
+}
+
+func (r *reporter) run(stop chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			r.flush(now, false)
+		case <-r.pending:
+			r.flush(time.Now(), false)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (i *incident) summary() string {
+	if i.count == 1 {
+		return fmt.Sprintf(":warning: `%s` failed: %s", i.source, i.err)
+	}
+
+	return fmt.Sprintf(":warning: `%s` failed %d times since the last report: %s", i.source, i.count, i.err)
+}
+
+// opsChannel returns the ops channel, which is in the default workspace unless
+// it has been qualified with the name of another.
+func (p *Pingu) opsChannel() MessageRef {
+	ch := p.config.GetString("pingu.ops_channel")
+
+	if ch == "" {
+		return MessageRef{}
+	}
+
+	return p.resolve(ch)
+}
+
+func (p *Pingu) postOps(text string) {
+	if ref := p.opsChannel(); ref.Channel != "" {
+		p.postMessage(ref, Text(text))
+	}
+}
+
+func downtime(d time.Duration) string {
+	return durafmt.ParseShort(d.Round(time.Second)).String()
+}
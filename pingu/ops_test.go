@@ -0,0 +1,83 @@
+package pingu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+
+	sent := make([]string, 0)
+	r := newReporter(time.Minute, func(text string) {
+		sent = append(sent, text)
+	})
+
+	now := time.Now()
+	err := errors.New("boom\nstack trace")
+
+	r.report("ping/ping", err, now)
+
+	if len(sent) != 0 || len(r.pending) != 1 {
+		t.Fatalf("report() should only signal run to post, got: %q", sent)
+	}
+
+	<-r.pending
+	r.flush(now, false)
+
+	if len(sent) != 1 || sent[0] != ":warning: `ping/ping` failed: boom" {
+		t.Fatalf("unexpected reports after first error: %q", sent)
+	}
+
+	r.report("ping/ping", err, now.Add(time.Second))
+	r.report("ping/ping", err, now.Add(2*time.Second))
+
+	if len(r.pending) != 0 {
+		t.Fatalf("repeated error signalled within the interval")
+	}
+
+	r.flush(now.Add(30*time.Second), false)
+
+	if len(sent) != 1 {
+		t.Fatalf("repeated error reported within the interval: %q", sent)
+	}
+
+	r.flush(now.Add(time.Minute), false)
+
+	if len(sent) != 2 || sent[1] != ":warning: `ping/ping` failed 2 times since the last report: boom" {
+		t.Fatalf("unexpected summary: %q", sent)
+	}
+
+	r.report("aoc/refresh", errors.New("timeout"), now.Add(time.Minute))
+
+	if len(r.pending) != 1 {
+		t.Fatalf("new error not signalled right away")
+	}
+
+	r.flush(now.Add(time.Minute), false)
+
+	if len(sent) != 3 || sent[2] != ":warning: `aoc/refresh` failed: timeout" {
+		t.Fatalf("new error not reported right away: %q", sent)
+	}
+}
+
+func TestReporterForceFlush(t *testing.T) {
+	t.Parallel()
+
+	sent := make([]string, 0)
+	r := newReporter(time.Hour, func(text string) {
+		sent = append(sent, text)
+	})
+
+	now := time.Now()
+
+	r.report("a", errors.New("one"), now)
+	r.flush(now, false)
+	r.report("a", errors.New("one"), now)
+	r.flush(now, true)
+
+	if len(sent) != 2 || sent[1] != ":warning: `a` failed: one" {
+		t.Fatalf("expected forced flush to report pending incidents, got %q", sent)
+	}
+}
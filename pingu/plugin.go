@@ -6,9 +6,13 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"plugin"
+	"strings"
 )
 
-const SymbolName = "New"
+const (
+	ConfigSymbolName = "Config"
+	SymbolName       = "New"
+)
 
 type Author struct {
 	Email string
@@ -25,12 +29,28 @@ type Plugin interface {
 
 type Plugins []Plugin
 
-func LoadPlugins(dir string) ([]func(*viper.Viper) Plugin, error) {
-	plugins := make([]func(*viper.Viper) Plugin, 0)
+// Factory creates a plugin, and is named after the file it was loaded from.
+type Factory struct {
+	Config *PluginConfig
+	Name   string
+	New    func(*viper.Viper) Plugin
+}
+
+// PluginConfig declares the configuration a plugin reads, through an exported
+// variable named Config next to its New function. A plugin only receives the
+// plugins.<name> sub-tree of the configuration, with the declared defaults,
+// unless it opts in to the global configuration.
+type PluginConfig struct {
+	Defaults map[string]interface{}
+	Global   bool
+}
+
+func LoadPlugins(dir string) ([]*Factory, error) {
+	factories := make([]*Factory, 0)
 	files, err := ioutil.ReadDir(dir)
 
 	if err != nil {
-		return plugins, errors.WithMessage(err, "unable to read directory")
+		return factories, errors.WithMessage(err, "unable to read directory")
 	}
 
 	for _, f := range files {
@@ -38,19 +58,19 @@ func LoadPlugins(dir string) ([]func(*viper.Viper) Plugin, error) {
 			continue
 		}
 
-		p, err := loadPlugin(filepath.Join(dir, f.Name()))
+		factory, err := loadPlugin(filepath.Join(dir, f.Name()))
 
 		if err != nil {
-			return plugins, errors.WithMessage(err, "unable to load plugin")
+			return factories, errors.WithMessage(err, "unable to load plugin")
 		}
 
-		plugins = append(plugins, p)
+		factories = append(factories, factory)
 	}
 
-	return plugins, nil
+	return factories, nil
 }
 
-func loadPlugin(path string) (func(*viper.Viper) Plugin, error) {
+func loadPlugin(path string) (*Factory, error) {
 	p, err := plugin.Open(path)
 
 	if err != nil {
@@ -74,5 +94,27 @@ func loadPlugin(path string) (func(*viper.Viper) Plugin, error) {
 		)
 	}
 
-	return factory, nil
+	loaded := &Factory{
+		Name: strings.TrimSuffix(filepath.Base(path), ".so"),
+		New:  factory,
+	}
+
+	// Declaring the configuration is optional, for backwards compatibility, in
+	// which case the plugin receives the global configuration.
+	if symbol, err := p.Lookup(ConfigSymbolName); err == nil {
+		config, ok := symbol.(*PluginConfig)
+
+		if !ok {
+			return nil, errors.Errorf(
+				"symbol %s (from %s) is %T, not pingu.PluginConfig",
+				ConfigSymbolName,
+				filepath.Base(path),
+				symbol,
+			)
+		}
+
+		loaded.Config = config
+	}
+
+	return loaded, nil
 }
@@ -0,0 +1,189 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"net"
+	"sync"
+	"time"
+)
+
+var errQueueFull = errors.New("outgoing message queue is full")
+
+type delivery struct {
+	result chan error
+	send   func() error
+}
+
+type queue struct {
+	backoff     time.Duration
+	channels    map[string]chan *delivery
+	idle        time.Duration
+	interval    time.Duration
+	logger      *logrus.Logger
+	mu          sync.Mutex
+	onDrop      func(ch string, err error)
+	rateLimited time.Duration
+	retries     int
+	size        int
+	sleep       func(time.Duration)
+}
+
+func newQueue(logger *logrus.Logger, interval time.Duration, retries int, size int) *queue {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	if retries < 0 {
+		retries = 0
+	}
+
+	if size <= 0 {
+		size = 100
+	}
+
+	return &queue{
+		backoff:     time.Second,
+		channels:    make(map[string]chan *delivery),
+		idle:        time.Minute,
+		interval:    interval,
+		logger:      logger,
+		rateLimited: 2 * time.Minute,
+		retries:     retries,
+		size:        size,
+		sleep:       time.Sleep,
+	}
+}
+
+// send queues fn behind everything else that is waiting to be sent to ch, and
+// blocks until it has either been delivered or given up on.
+func (q *queue) send(ch string, fn func() error) error {
+	d := &delivery{
+		result: make(chan error, 1),
+		send:   fn,
+	}
+
+	q.mu.Lock()
+
+	deliveries, ok := q.channels[ch]
+
+	if !ok {
+		deliveries = make(chan *delivery, q.size)
+		q.channels[ch] = deliveries
+
+		go q.work(ch, deliveries)
+	}
+
+	select {
+	case deliveries <- d:
+		q.mu.Unlock()
+	default:
+		q.mu.Unlock()
+		q.drop(ch, errQueueFull)
+
+		return errQueueFull
+	}
+
+	return <-d.result
+}
+
+// deliver sends the delivery, retrying transient errors with backoff. Rate
+// limits are waited out for as long as Slack asks, but only up to a total of
+// q.rateLimited, so that a persistent rate limit can't block the sender forever.
+func (q *queue) deliver(ch string, d *delivery) error {
+	attempt := 0
+	waited := time.Duration(0)
+
+	for {
+		err := d.send()
+
+		if err == nil {
+			return nil
+		}
+
+		if rateLimited, ok := err.(*slack.RateLimitedError); ok {
+			if waited+rateLimited.RetryAfter > q.rateLimited {
+				return err
+			}
+
+			waited += rateLimited.RetryAfter
+
+			q.logger.WithFields(logrus.Fields{
+				"channel":     ch,
+				"retry_after": rateLimited.RetryAfter,
+			}).Warn("Rate limited by Slack")
+
+			q.sleep(rateLimited.RetryAfter)
+
+			continue
+		}
+
+		if !isTransient(err) || attempt >= q.retries {
+			return err
+		}
+
+		q.sleep(q.backoff << uint(attempt))
+
+		attempt++
+	}
+}
+
+func (q *queue) drop(ch string, err error) {
+	q.logger.WithFields(logrus.Fields{
+		"channel": ch,
+		"error":   err,
+	}).Error("Message dropped")
+
+	if q.onDrop != nil {
+		q.onDrop(ch, err)
+	}
+}
+
+func (q *queue) work(ch string, deliveries chan *delivery) {
+	var last time.Time
+
+	for {
+		select {
+		case d := <-deliveries:
+			if wait := q.interval - time.Since(last); wait > 0 {
+				q.sleep(wait)
+			}
+
+			err := q.deliver(ch, d)
+			last = time.Now()
+
+			if err != nil {
+				q.drop(ch, err)
+			}
+
+			d.result <- err
+		case <-time.After(q.idle):
+			q.mu.Lock()
+
+			if len(deliveries) == 0 {
+				delete(q.channels, ch)
+				q.mu.Unlock()
+
+				return
+			}
+
+			q.mu.Unlock()
+		}
+	}
+}
+
+// Errors reported by the Slack API itself, such as channel_not_found, won't
+// go away by trying again, whereas network hiccups and server errors might.
+func isTransient(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *slack.RateLimitedError:
+		return true
+	case interface{ Retryable() bool }:
+		return e.Retryable()
+	case net.Error:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestQueue() *queue {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	q := newQueue(logger, time.Nanosecond, 3, 10)
+	q.sleep = func(time.Duration) {}
+
+	return q
+}
+
+func TestQueueOrdering(t *testing.T) {
+	q := newTestQueue()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	sent := make([]int, 0, 10)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// Block the worker so that the remaining deliveries pile up in the queue.
+	go q.send("C012345", func() error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	<-started
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			q.send("C012345", func() error {
+				mu.Lock()
+				sent = append(sent, i)
+				mu.Unlock()
+				return nil
+			})
+		}(i)
+
+		// Give each sender a chance to enqueue before the next one.
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	wg.Wait()
+
+	for i, n := range sent {
+		if i != n {
+			t.Fatalf("send() delivered out of order, got: %v.", sent)
+		}
+	}
+}
+
+func TestQueueRetries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		failures int
+		attempts int
+		fails    bool
+	}{
+		{"success", nil, 0, 1, false},
+		{"rate limited", &slack.RateLimitedError{RetryAfter: time.Second}, 5, 6, false},
+		{"rate limited exhausted", &slack.RateLimitedError{RetryAfter: time.Minute}, 10, 3, true},
+		{"transient", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, 2, 3, false},
+		{"transient exhausted", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, 10, 4, true},
+		{"permanent", errors.New("channel_not_found"), 10, 1, true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			attempts := 0
+
+			err := newTestQueue().send("C012345", func() error {
+				attempts++
+
+				if attempts <= testCase.failures {
+					return testCase.err
+				}
+
+				return nil
+			})
+
+			if (err != nil) != testCase.fails {
+				t.Errorf("send() returned an unexpected error: %v.", err)
+			}
+
+			if attempts != testCase.attempts {
+				t.Errorf("send() made an incorrect number of attempts, got: %d, want %d.", attempts, testCase.attempts)
+			}
+		})
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	q := newTestQueue()
+	q.size = 1
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go q.send("C012345", func() error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	<-started
+
+	go q.send("C012345", func() error { return nil })
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := q.send("C012345", func() error { return nil }); err != errQueueFull {
+		t.Errorf("send() was incorrect, got: %v, want %v.", err, errQueueFull)
+	}
+
+	close(release)
+}
@@ -0,0 +1,188 @@
+package pingu
+
+import (
+	"fmt"
+	"github.com/hako/durafmt"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+type Cooldown struct {
+	Channel time.Duration
+	Global  time.Duration
+	User    time.Duration
+}
+
+type cooldowns struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+type flood struct {
+	ignore   time.Duration
+	ignored  map[string]time.Time
+	interval time.Duration
+	messages int
+	mu       sync.Mutex
+	seen     map[string][]time.Time
+}
+
+func newCooldowns() *cooldowns {
+	return &cooldowns{
+		until: make(map[string]time.Time),
+	}
+}
+
+func newFlood(messages int, interval time.Duration, ignore time.Duration) *flood {
+	return &flood{
+		ignore:   ignore,
+		ignored:  make(map[string]time.Time),
+		interval: interval,
+		messages: messages,
+		seen:     make(map[string][]time.Time),
+	}
+}
+
+// take starts every cooldown in limits, unless one of them is still running,
+// in which case nothing is started and the time left on the longest running
+// cooldown is returned instead.
+func (c *cooldowns) take(limits map[string]time.Duration, now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var wait time.Duration
+
+	for key, duration := range limits {
+		if duration <= 0 {
+			continue
+		}
+
+		if left := c.until[key].Sub(now); left > wait {
+			wait = left
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	for key, duration := range limits {
+		if duration > 0 {
+			c.until[key] = now.Add(duration)
+		}
+	}
+
+	if len(c.until) > 10000 {
+		for key, until := range c.until {
+			if !until.After(now) {
+				delete(c.until, key)
+			}
+		}
+	}
+
+	return 0
+}
+
+// allow records a command sent by user and reports whether it should be
+// handled, and whether it was the command that got the user ignored.
+func (f *flood) allow(user string, now time.Time) (bool, bool) {
+	if f.messages <= 0 || user == "" {
+		return true, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if until, ok := f.ignored[user]; ok {
+		if now.Before(until) {
+			return false, false
+		}
+
+		delete(f.ignored, user)
+	}
+
+	seen := f.seen[user][:0]
+
+	for _, t := range f.seen[user] {
+		if now.Sub(t) < f.interval {
+			seen = append(seen, t)
+		}
+	}
+
+	seen = append(seen, now)
+
+	if len(seen) > f.messages {
+		delete(f.seen, user)
+		f.ignored[user] = now.Add(f.ignore)
+
+		return false, true
+	}
+
+	f.seen[user] = seen
+
+	return true, false
+}
+
+// start starts the cooldown, replacing any that is already running.
+func (c *cooldowns) start(key string, duration time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.until[key] = now.Add(duration)
+}
+
+// StartCooldown starts the global cooldown of one of the current plugin's
+// commands as if it had just been invoked, for commands that share their work
+// with a task.
+func (p *Pingu) StartCooldown(command *Command) {
+	if p.invocation == nil || p.invocation.plugin == nil || command.Cooldown.Global <= 0 {
+		return
+	}
+
+	p.cooldowns.start(cooldownPrefix(p.pluginID(p.invocation.plugin), command), command.Cooldown.Global, time.Now())
+}
+
+func (p *Pingu) cooldown(inv *invocation) time.Duration {
+	cooldown := inv.command.Cooldown
+	prefix := cooldownPrefix(p.pluginID(inv.plugin), inv.command)
+
+	return p.cooldowns.take(map[string]time.Duration{
+		prefix:                                  cooldown.Global,
+		prefix + "channel/" + inv.event.Channel: cooldown.Channel,
+		prefix + "user/" + inv.event.User:       cooldown.User,
+	}, time.Now())
+}
+
+func (p *Pingu) flooding(user string) (bool, string) {
+	ok, ignored := p.flood.allow(user, time.Now())
+
+	if ok || !ignored {
+		return !ok, ""
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"duration": p.flood.ignore,
+		"user":     user,
+	}).Warn("User ignored for flooding")
+
+	return true, fmt.Sprintf(
+		"Noot! Noot! You're sending commands too quickly, so I'll ignore you for %s.",
+		durafmt.ParseShort(p.flood.ignore),
+	)
+}
+
+func cooldownMessage(wait time.Duration) string {
+	if wait < time.Second {
+		wait = time.Second
+	}
+
+	return fmt.Sprintf(
+		"Noot! Noot! That command is on cooldown, please try again in %s.",
+		durafmt.ParseShort(wait.Round(time.Second)),
+	)
+}
+
+func cooldownPrefix(id string, command *Command) string {
+	return id + "/" + command.Trigger.String() + "/"
+}
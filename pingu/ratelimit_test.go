@@ -0,0 +1,85 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCooldownsTake(t *testing.T) {
+	c := newCooldowns()
+	now := time.Now()
+
+	limits := map[string]time.Duration{
+		"global":    time.Minute,
+		"user/U123": 0,
+	}
+
+	if wait := c.take(limits, now); wait != 0 {
+		t.Fatalf("take() was incorrect, got: %s, want 0s.", wait)
+	}
+
+	if wait := c.take(limits, now.Add(15*time.Second)); wait != 45*time.Second {
+		t.Errorf("take() was incorrect, got: %s, want 45s.", wait)
+	}
+
+	if wait := c.take(map[string]time.Duration{"user/U123": time.Minute}, now.Add(15*time.Second)); wait != 0 {
+		t.Errorf("take() was incorrect for an unrelated cooldown, got: %s, want 0s.", wait)
+	}
+
+	if wait := c.take(limits, now.Add(time.Minute)); wait != 0 {
+		t.Errorf("take() was incorrect after the cooldown expired, got: %s, want 0s.", wait)
+	}
+}
+
+func TestFloodAllow(t *testing.T) {
+	f := newFlood(3, 10*time.Second, time.Minute)
+	now := time.Now()
+
+	testCases := []struct {
+		offset  time.Duration
+		allowed bool
+		ignored bool
+	}{
+		{0, true, false},
+		{time.Second, true, false},
+		{2 * time.Second, true, false},
+		{3 * time.Second, false, true},
+		{4 * time.Second, false, false},
+		{63 * time.Second, true, false},
+		{64 * time.Second, true, false},
+	}
+
+	for i, testCase := range testCases {
+		allowed, ignored := f.allow("U012345", now.Add(testCase.offset))
+
+		if allowed != testCase.allowed || ignored != testCase.ignored {
+			t.Errorf("allow() #%d was incorrect, got: (%v, %v), want (%v, %v).", i, allowed, ignored, testCase.allowed, testCase.ignored)
+		}
+	}
+
+	if allowed, _ := f.allow("U678901", now.Add(4*time.Second)); !allowed {
+		t.Error("allow() ignored a different user.")
+	}
+}
+
+func TestStartCooldown(t *testing.T) {
+	plugin := &taskPlugin{}
+	command := &Command{Cooldown: Cooldown{Global: time.Minute}, Trigger: regexp.MustCompile("^!refresh$")}
+	p := &Pingu{state: &state{cooldowns: newCooldowns()}}
+
+	p.StartCooldown(command)
+
+	if len(p.cooldowns.until) != 0 {
+		t.Fatalf("StartCooldown() should not start anything without a plugin in scope.")
+	}
+
+	inv := &invocation{command: command, event: &slack.MessageEvent{}, plugin: plugin}
+
+	p.scoped(&invocation{plugin: plugin}).StartCooldown(command)
+
+	if wait := p.cooldown(inv); wait <= 0 {
+		t.Errorf("cooldown() was incorrect after StartCooldown(), got: %s, want more than 0s.", wait)
+	}
+}
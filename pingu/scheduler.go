@@ -0,0 +1,285 @@
+package pingu
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type job struct {
+	id        string
+	plugin    Plugin
+	running   int32
+	schedule  cron.Schedule
+	task      *Task
+	workspace *workspace
+}
+
+type jobState struct {
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+	LastRun      time.Time     `json:"last_run"`
+	Paused       bool          `json:"paused,omitempty"`
+}
+
+type scheduler struct {
+	cron   *cron.Cron
+	jobs   []*job
+	mu     sync.Mutex
+	pingu  *Pingu
+	sleep  func(time.Duration)
+	states map[string]*jobState
+}
+
+var slugRegex = regexp.MustCompile("[^a-z0-9]+")
+
+func newScheduler(p *Pingu) (*scheduler, error) {
+	s := &scheduler{
+		cron:   cron.New(),
+		jobs:   make([]*job, 0),
+		pingu:  p,
+		sleep:  time.Sleep,
+		states: make(map[string]*jobState),
+	}
+
+	s.reload()
+
+	for _, plugin := range p.plugins {
+		ws, ok := p.pluginWorkspace(plugin)
+
+		if !ok {
+			continue
+		}
+
+		for i, task := range plugin.Tasks() {
+			schedule, err := cron.ParseStandard(taskSpec(task))
+
+			if err != nil {
+				return nil, err
+			}
+
+			j := &job{
+				id:        taskID(p.pluginID(plugin), task, i),
+				plugin:    plugin,
+				schedule:  schedule,
+				task:      task,
+				workspace: ws,
+			}
+
+			s.jobs = append(s.jobs, j)
+			s.cron.Schedule(schedule, cron.FuncJob(func() {
+				s.run(j, false)
+			}))
+		}
+	}
+
+	return s, nil
+}
+
+func (s *scheduler) find(id string) (*job, bool) {
+	for _, j := range s.jobs {
+		if j.id == id {
+			return j, true
+		}
+	}
+
+	return nil, false
+}
+
+// reload replaces the state of every task with what was last persisted.
+func (s *scheduler) reload() {
+	states := make(map[string]*jobState)
+
+	if err := s.pingu.storage.load("scheduler", &states); err != nil {
+		s.pingu.logger.Error(err)
+		return
+	}
+
+	s.mu.Lock()
+	s.states = states
+	s.mu.Unlock()
+}
+
+// resume is called whenever a connection to the workspace has been
+// established. Its interval tasks are run right away, as are its tasks that want
+// to catch up on a run that was missed while disconnected.
+func (s *scheduler) resume(ws *workspace, now time.Time) {
+	for _, j := range s.jobs {
+		if j.workspace != ws {
+			continue
+		}
+
+		if j.task.Spec == "" || (j.task.CatchUp && s.missed(j, now)) {
+			go s.run(j, false)
+		}
+	}
+
+	s.cron.Start()
+}
+
+func (s *scheduler) missed(j *job, now time.Time) bool {
+	state := s.state(j.id)
+
+	return !state.LastRun.IsZero() && !j.schedule.Next(state.LastRun).After(now)
+}
+
+// run runs the job, unless it has been paused or its workspace is disconnected.
+// Paused jobs can still be run manually by passing force.
+func (s *scheduler) run(j *job, force bool) error {
+	logger := s.pingu.pluginLogger(j.plugin).WithField("task", j.id)
+
+	if !force && !s.pingu.Leader() {
+		logger.Debug("Task skipped, not the leader")
+		return nil
+	}
+
+	if !force && !j.workspace.online() {
+		logger.Debug("Task skipped, workspace is disconnected")
+		return nil
+	}
+
+	if !force && s.state(j.id).Paused {
+		logger.Info("Task skipped, task is paused")
+		return nil
+	}
+
+	if atomic.AddInt32(&j.running, 1) > 1 && j.task.SkipIfRunning {
+		atomic.AddInt32(&j.running, -1)
+		logger.Warn("Task skipped, previous run still in progress")
+		return nil
+	}
+
+	if j.task.Jitter > 0 && !force {
+		s.sleep(time.Duration(rand.Int63n(int64(j.task.Jitter))))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if j.task.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), j.task.Timeout)
+	}
+
+	defer cancel()
+
+	started := time.Now()
+	result := make(chan error, 1)
+
+	go func() {
+		defer atomic.AddInt32(&j.running, -1)
+
+		result <- safely(func() error {
+			return j.task.Func(s.pingu.on(j.workspace).scoped(&invocation{ctx: ctx, plugin: j.plugin}))
+		})
+	}()
+
+	var err error
+
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = errors.Errorf("timed out after %s", j.task.Timeout)
+	}
+
+	duration := time.Since(started)
+
+	if err != nil {
+		s.pingu.fail(j.id, logrus.Fields{
+			"duration": duration,
+			"plugin":   j.plugin.Name(),
+			"task":     j.id,
+		}, err, "Task failed")
+	} else {
+		logger.WithField("duration", duration).Info("Task executed")
+	}
+
+	s.update(j.id, func(state *jobState) {
+		state.LastDuration = duration
+		state.LastError = ""
+		state.LastRun = started
+
+		if err != nil {
+			state.LastError = err.Error()
+		}
+	})
+
+	return err
+}
+
+func (s *scheduler) setPaused(id string, paused bool) bool {
+	if _, ok := s.find(id); !ok {
+		return false
+	}
+
+	s.update(id, func(state *jobState) {
+		state.Paused = paused
+	})
+
+	return true
+}
+
+func (s *scheduler) state(id string) jobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, ok := s.states[id]; ok {
+		return *state
+	}
+
+	return jobState{}
+}
+
+func (s *scheduler) stop() {
+	s.cron.Stop()
+}
+
+func (s *scheduler) update(id string, fn func(state *jobState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[id]
+
+	if !ok {
+		state = &jobState{}
+		s.states[id] = state
+	}
+
+	fn(state)
+
+	if err := s.pingu.storage.save("scheduler", s.states); err != nil {
+		s.pingu.logger.Error(err)
+	}
+}
+
+func slug(s string) string {
+	return strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func taskID(plugin string, task *Task, i int) string {
+	if task.Name != "" {
+		return plugin + "/" + slug(task.Name)
+	}
+
+	return fmt.Sprintf("%s/%d", plugin, i)
+}
+
+func taskSpec(task *Task) string {
+	spec := task.Spec
+
+	if spec == "" {
+		spec = fmt.Sprintf("@every %s", task.Interval.String())
+	}
+
+	if task.Location != nil {
+		spec = "CRON_TZ=" + task.Location.String() + " " + spec
+	}
+
+	return spec
+}
@@ -0,0 +1,175 @@
+package pingu
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+type taskPlugin struct {
+	Plugin
+	tasks Tasks
+}
+
+func (pl *taskPlugin) Name() string {
+	return "Advent of Code"
+}
+
+func (pl *taskPlugin) Tasks() Tasks {
+	return pl.tasks
+}
+
+func (pl *taskPlugin) Version() string {
+	return "dev"
+}
+
+func newTestScheduler(t *testing.T, tasks Tasks) *scheduler {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	ws := newWorkspace(defaultWorkspace, "", nil, newStorage(""))
+	ws.markConnected(time.Now(), "", "")
+
+	p := &Pingu{state: &state{
+		config:     viper.New(),
+		failures:   make(map[string]int),
+		logger:     logger,
+		plugins:    Plugins{&taskPlugin{tasks: tasks}},
+		reporter:   newReporter(0, func(string) {}),
+		storage:    newStorage(""),
+		workspaces: []*workspace{ws},
+	}}
+
+	s, err := newScheduler(p)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestTaskID(t *testing.T) {
+	testCases := []struct {
+		task     *Task
+		expected string
+	}{
+		{&Task{}, "advent-of-code/2"},
+		{&Task{Name: "Refresh (December)"}, "advent-of-code/refresh-december"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := taskID("advent-of-code", testCase.task, 2); actual != testCase.expected {
+			t.Errorf("taskID() was incorrect, got: %s, want %s.", actual, testCase.expected)
+		}
+	}
+}
+
+func TestSchedulerMissed(t *testing.T) {
+	s := newTestScheduler(t, Tasks{&Task{CatchUp: true, Spec: "0 5 * * *", Location: time.UTC}})
+	j := s.jobs[0]
+	now := time.Date(2020, 12, 2, 6, 0, 0, 0, time.UTC)
+
+	if s.missed(j, now) {
+		t.Error("missed() was incorrect for a task that has never run.")
+	}
+
+	s.states[j.id] = &jobState{LastRun: time.Date(2020, 12, 2, 5, 0, 0, 0, time.UTC)}
+
+	if s.missed(j, now) {
+		t.Error("missed() was incorrect for a task that has already run.")
+	}
+
+	s.states[j.id] = &jobState{LastRun: time.Date(2020, 12, 1, 5, 0, 0, 0, time.UTC)}
+
+	if !s.missed(j, now) {
+		t.Error("missed() was incorrect for a task that missed a run.")
+	}
+}
+
+func TestSchedulerSkipIfRunning(t *testing.T) {
+	release := make(chan struct{})
+	runs := make(chan struct{}, 2)
+
+	s := newTestScheduler(t, Tasks{&Task{
+		Func: func(pi *Pingu) error {
+			runs <- struct{}{}
+			<-release
+			return nil
+		},
+		Interval:      time.Hour,
+		SkipIfRunning: true,
+	}})
+
+	go s.run(s.jobs[0], false)
+
+	<-runs
+
+	s.run(s.jobs[0], false)
+	close(release)
+
+	select {
+	case <-runs:
+		t.Error("run() was incorrect, a task ran while it was still running.")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSchedulerPaused(t *testing.T) {
+	runs := 0
+
+	s := newTestScheduler(t, Tasks{&Task{
+		Func: func(pi *Pingu) error {
+			runs++
+			return errors.New("refresh failed")
+		},
+		Interval: time.Hour,
+		Name:     "refresh",
+	}})
+
+	if !s.setPaused("advent-of-code/refresh", true) {
+		t.Fatal("setPaused() was unable to find the task.")
+	}
+
+	s.run(s.jobs[0], false)
+
+	if runs != 0 {
+		t.Errorf("run() was incorrect, a paused task ran %d times.", runs)
+	}
+
+	if err := s.run(s.jobs[0], true); err == nil || runs != 1 {
+		t.Errorf("run() was incorrect, a forced run of a paused task returned %v after %d runs.", err, runs)
+	}
+
+	if state := s.state("advent-of-code/refresh"); state.LastError != "refresh failed" || state.LastRun.IsZero() {
+		t.Errorf("run() did not record the result, got: %+v.", state)
+	}
+}
+
+func TestSchedulerDisconnected(t *testing.T) {
+	runs := 0
+
+	s := newTestScheduler(t, Tasks{&Task{
+		Func: func(pi *Pingu) error {
+			runs++
+			return nil
+		},
+		Interval: time.Hour,
+	}})
+
+	s.jobs[0].workspace.markDisconnected(time.Now().Add(time.Second))
+	s.run(s.jobs[0], false)
+
+	if runs != 0 {
+		t.Errorf("run() was incorrect, a task ran %d times while its workspace was disconnected.", runs)
+	}
+
+	s.run(s.jobs[0], true)
+
+	if runs != 1 {
+		t.Errorf("run() was incorrect, a forced run while disconnected ran %d times.", runs)
+	}
+}
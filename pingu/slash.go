@@ -0,0 +1,129 @@
+package pingu
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/slack-go/slack"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+func (p *Pingu) receiveSlashCommand(pi *Pingu, w http.ResponseWriter, r *http.Request) {
+	body, err := p.verifyRequest(r)
+
+	if err != nil {
+		p.logger.WithField("remote", r.RemoteAddr).Warn(err)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	s, err := slack.SlashCommandParse(r)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	p = p.on(p.workspaceByTeam(s.TeamID))
+
+	for _, plugin := range p.plugins {
+		if !p.enabled(plugin) {
+			continue
+		}
+
+		for _, command := range plugin.Commands() {
+			if command.Slash == "" || p.Slash(plugin, command) != s.Command {
+				continue
+			}
+
+			ev := newSlashCommandEvent(command, s)
+
+			if !command.Trigger.MatchString(ev.Text) {
+				writeSlashResponse(w, "Noot! Noot! I didn't understand that. "+command.Description)
+				return
+			}
+
+			if flooding, notice := p.flooding(ev.User); flooding {
+				if notice == "" {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+
+				writeSlashResponse(w, notice)
+				return
+			}
+
+			inv := &invocation{
+				command:     command,
+				event:       ev,
+				plugin:      plugin,
+				responseURL: s.ResponseURL,
+			}
+
+			if command.Admin && !p.isAdmin(ev.User) {
+				go p.audit(inv, auditDenied, nil, 0)
+				writeSlashResponse(w, adminMessage)
+				return
+			}
+
+			if notice := p.restricted(command, ev.Channel); notice != "" {
+				writeSlashResponse(w, notice)
+				return
+			}
+
+			if wait := p.cooldown(inv); wait > 0 {
+				writeSlashResponse(w, cooldownMessage(wait))
+				return
+			}
+
+			// Slack expects an acknowledgement within three seconds, so the
+			// command responds through the response URL once it's done.
+			w.WriteHeader(http.StatusOK)
+
+			go p.invoke(inv)
+
+			return
+		}
+	}
+
+	writeSlashResponse(w, "Noot! Noot! I don't know that command.")
+}
+
+// Slash commands are mapped onto the same text a message would have had, so
+// "/lb 2019" triggers the command matching "!leaderboard 2019". The word is
+// taken from the command's trigger, as the slash command may be named
+// differently or have been namespaced, falling back to the slash command's own
+// name for triggers that don't start with one.
+func newSlashCommandEvent(command *Command, s slack.SlashCommand) *slack.MessageEvent {
+	word := commandWord(command)
+
+	if word == "" {
+		word = strings.TrimPrefix(command.Slash, "/")
+	}
+
+	text := "!" + word
+
+	if args := strings.TrimSpace(s.Text); args != "" {
+		text += " " + args
+	}
+
+	return &slack.MessageEvent{
+		Msg: slack.Msg{
+			Channel: s.ChannelID,
+			Team:    s.TeamID,
+			Text:    text,
+			User:    s.UserID,
+		},
+	}
+}
+
+func writeSlashResponse(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(&slack.Msg{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         text,
+	})
+}
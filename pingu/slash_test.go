@@ -0,0 +1,47 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"regexp"
+	"testing"
+)
+
+func TestNewSlashCommandEvent(t *testing.T) {
+	leaderboard := regexp.MustCompile("^!leaderboard(?: (\\d{4}))?$")
+	testCases := []struct {
+		command  string
+		trigger  *regexp.Regexp
+		text     string
+		expected string
+	}{
+		{"/leaderboard", leaderboard, "", "!leaderboard"},
+		{"/leaderboard", leaderboard, "2019", "!leaderboard 2019"},
+		{"/leaderboard", leaderboard, "  2019 ", "!leaderboard 2019"},
+		{"/lb", leaderboard, "2019", "!leaderboard 2019"},
+		{"/jira", regexp.MustCompile("(?i)[a-z]+-\\d+"), "ABC-1", "!jira ABC-1"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.command+" "+testCase.text, func(t *testing.T) {
+			t.Parallel()
+
+			command := &Command{Slash: testCase.command, Trigger: testCase.trigger}
+			ev := newSlashCommandEvent(command, slack.SlashCommand{
+				ChannelID: "C012345",
+				Command:   testCase.command,
+				Text:      testCase.text,
+				UserID:    "U012345",
+			})
+
+			if ev.Text != testCase.expected {
+				t.Errorf("newSlashCommandEvent() was incorrect, got: %v, want %v.", ev.Text, testCase.expected)
+			}
+
+			if ev.Channel != "C012345" || ev.User != "U012345" {
+				t.Errorf("newSlashCommandEvent() was incorrect, got: %v/%v, want %v/%v.", ev.Channel, ev.User, "C012345", "U012345")
+			}
+		})
+	}
+}
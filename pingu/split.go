@@ -0,0 +1,188 @@
+package pingu
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const codeFence = "```"
+const maxBlocks = 50
+const maxSectionText = 3000
+
+func (m RichMessage) split(limit int) []RichMessage {
+	if len(m.Blocks) == 0 {
+		chunks := splitText(m.Text, limit)
+		messages := make([]RichMessage, len(chunks))
+
+		for i, chunk := range chunks {
+			messages[i] = Text(chunk)
+		}
+
+		return messages
+	}
+
+	blocks := make([]Block, 0, len(m.Blocks))
+
+	for _, b := range m.Blocks {
+		if s, ok := b.(Section); ok && len(s.Text) > maxSectionText {
+			blocks = append(blocks, s.split()...)
+			continue
+		}
+
+		blocks = append(blocks, b)
+	}
+
+	messages := make([]RichMessage, 0, len(blocks)/maxBlocks+1)
+
+	for start := 0; start < len(blocks); start += maxBlocks {
+		end := start + maxBlocks
+
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+
+		chunk := RichMessage{Blocks: blocks[start:end]}
+
+		if start == 0 && m.Text != "" {
+			chunk.Text = m.Text
+		}
+
+		chunk.Text = truncate(chunk.fallback(), limit)
+		messages = append(messages, chunk)
+	}
+
+	return messages
+}
+
+func (s Section) split() []Block {
+	chunks := splitText(s.Text, maxSectionText)
+	sections := make([]Block, len(chunks))
+
+	for i, chunk := range chunks {
+		section := Section{Text: chunk}
+
+		if i == 0 {
+			section.Accessory = s.Accessory
+			section.ID = s.ID
+		}
+
+		if i == len(chunks)-1 {
+			section.Fields = s.Fields
+		}
+
+		sections[i] = section
+	}
+
+	return sections
+}
+
+// splitText splits text into chunks of at most limit bytes, preferably at line
+// boundaries. Code blocks that span multiple chunks are closed at the end of
+// one chunk and reopened at the start of the next, so that each chunk renders
+// on its own.
+func splitText(text string, limit int) []string {
+	if limit <= 0 || len(text) <= limit {
+		return []string{text}
+	}
+
+	// Leave room for closing a code block that is still open.
+	closing := len(codeFence) + 1
+
+	if limit <= 2*closing {
+		closing = 0
+	}
+
+	chunks := make([]string, 0, len(text)/limit+1)
+	lines := strings.SplitAfter(text, "\n")
+
+	var b strings.Builder
+
+	open := false
+	prefix := 0
+
+	flush := func() {
+		chunk := strings.TrimSuffix(b.String(), "\n")
+
+		if open {
+			chunk += "\n" + codeFence
+		}
+
+		chunks = append(chunks, chunk)
+		b.Reset()
+		prefix = 0
+
+		if open {
+			b.WriteString(codeFence + "\n")
+			prefix = b.Len()
+		}
+	}
+
+	for _, line := range lines {
+		for line != "" {
+			toggles := strings.Count(line, codeFence)%2 == 1
+			size := b.Len() + len(strings.TrimSuffix(line, "\n"))
+
+			if open != toggles {
+				size += closing
+			}
+
+			if size <= limit {
+				b.WriteString(line)
+
+				if toggles {
+					open = !open
+				}
+
+				line = ""
+
+				continue
+			}
+
+			if b.Len() > prefix {
+				flush()
+				continue
+			}
+
+			cut := runeBoundary(line, limit-closing-b.Len())
+			b.WriteString(line[:cut])
+
+			if strings.Count(line[:cut], codeFence)%2 == 1 {
+				open = !open
+			}
+
+			line = line[cut:]
+
+			flush()
+		}
+	}
+
+	if b.Len() > prefix {
+		chunks = append(chunks, strings.TrimSuffix(b.String(), "\n"))
+	}
+
+	return chunks
+}
+
+func runeBoundary(s string, n int) int {
+	if n >= len(s) {
+		return len(s)
+	}
+
+	for i := n; i > 0; i-- {
+		if utf8.RuneStart(s[i]) {
+			return i
+		}
+	}
+
+	_, size := utf8.DecodeRuneInString(s)
+
+	return size
+}
+
+func truncate(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+
+	return s[:runeBoundary(s, limit-len("…"))] + "…"
+}
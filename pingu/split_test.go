@@ -0,0 +1,84 @@
+package pingu
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		limit    int
+		expected []string
+	}{
+		{"short", "foo\nbar", 100, []string{"foo\nbar"}},
+		{"lines", "foo\nbar\nbaz", 10, []string{"foo\nbar", "baz"}},
+		{"long line", "foobarbazqux", 10, []string{"foobar", "bazqux"}},
+		{"code block", "foo\n```\nbar\nbaz\n```", 16, []string{"foo\n```\nbar\n```", "```\nbaz\n```"}},
+		{"multibyte", "åäöåäö", 10, []string{"åäö", "åäö"}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := splitText(testCase.text, testCase.limit)
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("splitText() was incorrect, got: %q, want %q.", actual, testCase.expected)
+			}
+
+			for _, chunk := range actual {
+				if len(chunk) > testCase.limit {
+					t.Errorf("splitText() returned a chunk of %d bytes, limit was %d.", len(chunk), testCase.limit)
+				}
+
+				if strings.Count(chunk, codeFence)%2 != 0 {
+					t.Errorf("splitText() returned a chunk with unbalanced code fences: %q.", chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestRichMessageSplit(t *testing.T) {
+	blocks := make([]Block, 0, 60)
+
+	blocks = append(blocks, Section{ID: "first", Text: strings.Repeat("foo\n", 1000)})
+
+	for len(blocks) < 60 {
+		blocks = append(blocks, Divider{})
+	}
+
+	messages := RichMessage{Blocks: blocks, Text: "Fallback"}.split(4000)
+
+	if len(messages) != 2 {
+		t.Fatalf("split() returned %d messages, want 2.", len(messages))
+	}
+
+	if n := len(messages[0].Blocks); n != maxBlocks {
+		t.Errorf("split() returned %d blocks in the first message, want %d.", n, maxBlocks)
+	}
+
+	if n := len(messages[0].Blocks) + len(messages[1].Blocks); n != 61 {
+		t.Errorf("split() returned %d blocks in total, want 61.", n)
+	}
+
+	if messages[0].Text != "Fallback" {
+		t.Errorf("split() did not keep the fallback text, got: %q.", messages[0].Text)
+	}
+
+	for _, b := range messages[0].Blocks[:2] {
+		if s := b.(Section); len(s.Text) > maxSectionText {
+			t.Errorf("split() returned a section with %d characters of text.", len(s.Text))
+		}
+	}
+
+	if s := messages[0].Blocks[0].(Section); s.ID != "first" {
+		t.Errorf("split() did not keep the ID of the first section, got: %q.", s.ID)
+	}
+}
@@ -0,0 +1,122 @@
+package pingu
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// storage persists JSON documents as files in the configured data path. If no
+// path is configured nothing is persisted and every load comes up empty. When
+// replicas share the path, only the one that is writable persists anything.
+type storage struct {
+	mu       sync.Mutex
+	path     string
+	writable func() bool
+}
+
+var errNoPlugin = errors.New("storage is only available to plugins")
+
+func newStorage(path string) *storage {
+	return &storage{path: path}
+}
+
+func (s *storage) load(name string, v interface{}) error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := ioutil.ReadFile(s.file(name))
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to read "+name)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.WithMessage(err, "unable to decode "+name)
+	}
+
+	return nil
+}
+
+func (s *storage) save(name string, v interface{}) error {
+	if s.path == "" || (s.writable != nil && !s.writable()) {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to encode "+name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.path, 0755); err != nil {
+		return errors.WithMessage(err, "unable to create data path")
+	}
+
+	// Writing to a temporary file first ensures that a crash halfway through
+	// never leaves a truncated file behind.
+	tmp, err := ioutil.TempFile(s.path, name+".*.tmp")
+
+	if err != nil {
+		return errors.WithMessage(err, "unable to write "+name)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return errors.WithMessage(err, "unable to write "+name)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return errors.WithMessage(err, "unable to write "+name)
+	}
+
+	if err := os.Rename(tmp.Name(), s.file(name)); err != nil {
+		os.Remove(tmp.Name())
+
+		return errors.WithMessage(err, "unable to write "+name)
+	}
+
+	return nil
+}
+
+// Load decodes the named document that the current plugin saved earlier into
+// v, leaving v as is if there is none. Every instance of a plugin has its own
+// documents.
+func (p *Pingu) Load(name string, v interface{}) error {
+	if p.invocation == nil || p.invocation.plugin == nil {
+		return errNoPlugin
+	}
+
+	return p.storage.load(p.pluginID(p.invocation.plugin)+"."+name, v)
+}
+
+// Save persists v as the named document of the current plugin.
+func (p *Pingu) Save(name string, v interface{}) error {
+	if p.invocation == nil || p.invocation.plugin == nil {
+		return errNoPlugin
+	}
+
+	return p.storage.save(p.pluginID(p.invocation.plugin)+"."+name, v)
+}
+
+func (s *storage) file(name string) string {
+	return filepath.Join(s.path, name+".json")
+}
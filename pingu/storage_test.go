@@ -0,0 +1,76 @@
+package pingu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pingu")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	s := newStorage(dir)
+	expected := map[string]time.Time{"aoc/0": time.Date(2020, 12, 1, 5, 0, 0, 0, time.UTC)}
+
+	if err := s.save("scheduler", expected); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make(map[string]time.Time)
+
+	if err := s.load("scheduler", &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if !actual["aoc/0"].Equal(expected["aoc/0"]) {
+		t.Errorf("load() was incorrect, got: %v, want %v.", actual, expected)
+	}
+}
+
+func TestPluginStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pingu")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	first := &taskPlugin{}
+	second := &taskPlugin{}
+	p := &Pingu{state: &state{
+		instances: map[Plugin]*instance{second: {name: "work", namespace: "work"}},
+		storage:   newStorage(dir),
+	}}
+
+	if err := p.Save("owner", 1); err != errNoPlugin {
+		t.Errorf("Save() was incorrect, got: %v, want %v.", err, errNoPlugin)
+	}
+
+	if err := p.scoped(&invocation{plugin: first}).Save("owner", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.scoped(&invocation{plugin: second}).Save("owner", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	for plugin, expected := range map[Plugin]int{first: 1, second: 2} {
+		var actual int
+
+		if err := p.scoped(&invocation{plugin: plugin}).Load("owner", &actual); err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("Load() was incorrect, got: %d, want %d.", actual, expected)
+		}
+	}
+}
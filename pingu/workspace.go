@@ -0,0 +1,258 @@
+package pingu
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultWorkspace is the name of the workspace configured through the
+// top-level slack.token, for setups that only connect to a single workspace.
+const defaultWorkspace = "default"
+
+// workspace is a single connection to a Slack workspace, along with everything
+// that is only valid within it. The state of the connection is written by the
+// event loop and read from everywhere else, so it's guarded by mu.
+type workspace struct {
+	client         *slack.Client
+	connectedAt    time.Time
+	connections    *connections
+	directory      *Directory
+	directs        map[string]string
+	directsMu      sync.Mutex
+	disconnectedAt time.Time
+	latency        time.Duration
+	mu             sync.Mutex
+	name           string
+	plugins        map[string]bool
+	self           string
+	team           string
+	token          string
+}
+
+func newWorkspace(name string, token string, plugins []string, storage *storage) *workspace {
+	ws := &workspace{
+		client:    slack.New(token),
+		directory: newDirectory(),
+		directs:   make(map[string]string),
+		name:      name,
+		token:     token,
+	}
+
+	if name == defaultWorkspace {
+		ws.connections = newConnections(storage, "connections")
+	} else {
+		ws.connections = newConnections(storage, "connections-"+name)
+	}
+
+	if len(plugins) > 0 {
+		ws.plugins = make(map[string]bool, len(plugins))
+
+		for _, plugin := range plugins {
+			ws.plugins[slug(plugin)] = true
+		}
+	}
+
+	return ws
+}
+
+// newWorkspaces creates a workspace for every entry in slack.workspaces, or
+// a single one from slack.token if there are none. The workspace named by
+// slack.default_workspace, or else the first one by name, comes first.
+func newWorkspaces(config *viper.Viper, storage *storage) []*workspace {
+	configured := config.GetStringMap("slack.workspaces")
+
+	if len(configured) == 0 {
+		return []*workspace{newWorkspace(defaultWorkspace, config.GetString("slack.token"), nil, storage)}
+	}
+
+	names := make([]string, 0, len(configured))
+
+	for name := range configured {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	primary := config.GetString("slack.default_workspace")
+	workspaces := make([]*workspace, 0, len(names))
+
+	for _, name := range names {
+		ws := newWorkspace(
+			name,
+			config.GetString("slack.workspaces."+name+".token"),
+			config.GetStringSlice("slack.workspaces."+name+".plugins"),
+			storage,
+		)
+
+		if name == primary {
+			workspaces = append([]*workspace{ws}, workspaces...)
+		} else {
+			workspaces = append(workspaces, ws)
+		}
+	}
+
+	return workspaces
+}
+
+func (ws *workspace) currentLatency() time.Duration {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.latency
+}
+
+// markConnected records that the workspace has been connected to as the user
+// in the team, and returns when it was previously connected.
+func (ws *workspace) markConnected(now time.Time, self string, team string) time.Time {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	previous := ws.connectedAt
+	ws.connectedAt = now
+	ws.self = self
+	ws.team = team
+
+	return previous
+}
+
+func (ws *workspace) markDisconnected(now time.Time) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.disconnectedAt = now
+}
+
+// online reports whether the workspace is currently connected.
+func (ws *workspace) online() bool {
+	connectedAt, disconnectedAt := ws.times()
+
+	return connectedAt.After(disconnectedAt)
+}
+
+func (ws *workspace) setLatency(latency time.Duration) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.latency = latency
+}
+
+func (ws *workspace) teamID() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.team
+}
+
+// times returns when the workspace was last connected and disconnected.
+func (ws *workspace) times() (time.Time, time.Time) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.connectedAt, ws.disconnectedAt
+}
+
+func (ws *workspace) userID() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.self
+}
+
+// enabled reports whether the plugin is enabled in the current workspace. Every
+// plugin is enabled unless the workspace lists which ones to enable.
+func (p *Pingu) enabled(plugin Plugin) bool {
+	ws := p.current()
+
+	if _, ok := plugin.(*adminPlugin); ok || ws.plugins == nil {
+		return true
+	}
+
+	return ws.plugins[p.pluginID(plugin)]
+}
+
+// pluginWorkspace returns the first workspace that the plugin is enabled in,
+// which is the one its tasks run in.
+func (p *Pingu) pluginWorkspace(plugin Plugin) (*workspace, bool) {
+	for _, ws := range p.workspaces {
+		if p.on(ws).enabled(plugin) {
+			return ws, true
+		}
+	}
+
+	return nil, false
+}
+
+// Workspace returns the name of the workspace that the current command, event
+// or interaction came from.
+func (p *Pingu) Workspace() string {
+	return p.current().name
+}
+
+// current returns the workspace the Pingu has been scoped to, or the default
+// one if it hasn't been scoped to any.
+func (p *Pingu) current() *workspace {
+	if p.workspace != nil {
+		return p.workspace
+	}
+
+	return p.workspaces[0]
+}
+
+func (p *Pingu) findWorkspace(name string) (*workspace, bool) {
+	for _, ws := range p.workspaces {
+		if ws.name == name {
+			return ws, true
+		}
+	}
+
+	return nil, false
+}
+
+// on returns a copy of the Pingu scoped to the workspace.
+func (p *Pingu) on(ws *workspace) *Pingu {
+	return &Pingu{
+		state:      p.state,
+		invocation: p.invocation,
+		workspace:  ws,
+	}
+}
+
+// resolve turns a channel, optionally qualified with the name of a workspace
+// as in "work:#general", into a reference to it.
+func (p *Pingu) resolve(ch string) MessageRef {
+	ws := p.current()
+
+	if i := strings.Index(ch, ":"); i > 0 {
+		if named, ok := p.findWorkspace(ch[:i]); ok {
+			ws = named
+			ch = ch[i+1:]
+		}
+	}
+
+	return MessageRef{Channel: ws.directory.ChannelID(ch), Workspace: ws.name}
+}
+
+// workspaceByTeam returns the workspace connected to the team, or the default
+// one if none of them are.
+func (p *Pingu) workspaceByTeam(team string) *workspace {
+	for _, ws := range p.workspaces {
+		if id := ws.teamID(); id != "" && id == team {
+			return ws
+		}
+	}
+
+	return p.workspaces[0]
+}
+
+// workspaceFor returns the workspace the referenced message belongs to.
+func (p *Pingu) workspaceFor(ref MessageRef) *workspace {
+	if ws, ok := p.findWorkspace(ref.Workspace); ok {
+		return ws
+	}
+
+	return p.current()
+}
@@ -0,0 +1,139 @@
+package pingu
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+	"time"
+)
+
+func TestNewWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	legacy := viper.New()
+	legacy.Set("slack.token", "xoxb-legacy")
+
+	multiple := viper.New()
+	multiple.Set("slack.default_workspace", "work")
+	multiple.Set("slack.workspaces", map[string]interface{}{
+		"community": map[string]interface{}{"token": "xoxb-community", "plugins": []string{"aoc"}},
+		"work":      map[string]interface{}{"token": "xoxb-work"},
+	})
+
+	testCases := []struct {
+		name     string
+		config   *viper.Viper
+		expected []string
+	}{
+		{"legacy", legacy, []string{defaultWorkspace}},
+		{"multiple", multiple, []string{"work", "community"}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			workspaces := newWorkspaces(testCase.config, newStorage(""))
+
+			if len(workspaces) != len(testCase.expected) {
+				t.Fatalf("newWorkspaces() was incorrect, got: %d workspaces, want %d.", len(workspaces), len(testCase.expected))
+			}
+
+			for i, name := range testCase.expected {
+				if workspaces[i].name != name {
+					t.Errorf("newWorkspaces()[%d] was incorrect, got: %s, want %s.", i, workspaces[i].name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkspaceEnabled(t *testing.T) {
+	t.Parallel()
+
+	aoc := &taskPlugin{}
+	jira := &taskPlugin{}
+	ws := newWorkspace("community", "", []string{"Jira"}, newStorage(""))
+	p := (&Pingu{state: &state{instances: map[Plugin]*instance{
+		aoc:  {first: true, name: "aoc"},
+		jira: {first: true, name: "jira"},
+	}}}).on(ws)
+
+	testCases := []struct {
+		plugin   Plugin
+		expected bool
+	}{
+		{aoc, false},
+		{jira, true},
+		{&adminPlugin{}, true},
+	}
+
+	for _, testCase := range testCases {
+		if actual := p.enabled(testCase.plugin); testCase.expected != actual {
+			t.Errorf("enabled(%s) was incorrect, got: %v, want %v.", testCase.plugin.Name(), actual, testCase.expected)
+		}
+	}
+
+	if !p.on(newWorkspace(defaultWorkspace, "", nil, newStorage(""))).enabled(&taskPlugin{}) {
+		t.Errorf("enabled() was incorrect, plugins should be enabled by default.")
+	}
+}
+
+func TestPluginWorkspace(t *testing.T) {
+	t.Parallel()
+
+	storage := newStorage("")
+	work := newWorkspace("work", "", []string{"jira"}, storage)
+	community := newWorkspace("community", "", []string{"aoc"}, storage)
+	plugin := &taskPlugin{}
+	p := &Pingu{state: &state{
+		instances:  map[Plugin]*instance{plugin: {first: true, name: "aoc"}},
+		workspaces: []*workspace{work, community},
+	}}
+
+	if ws, ok := p.pluginWorkspace(plugin); !ok || ws != community {
+		t.Errorf("pluginWorkspace() was incorrect, got: %v, want %s.", ws, community.name)
+	}
+
+	p.workspaces = []*workspace{work}
+
+	if _, ok := p.pluginWorkspace(plugin); ok {
+		t.Errorf("pluginWorkspace() was incorrect, the plugin isn't enabled in any workspace.")
+	}
+}
+
+func TestWorkspaceConnectionState(t *testing.T) {
+	t.Parallel()
+
+	ws := newWorkspace(defaultWorkspace, "", nil, newStorage(""))
+	now := time.Now()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			ws.online()
+			ws.currentLatency()
+			ws.teamID()
+		}
+	}()
+
+	if previous := ws.markConnected(now, "U012345", "T012345"); !previous.IsZero() {
+		t.Errorf("markConnected() was incorrect, got: %s, want the zero time.", previous)
+	}
+
+	ws.setLatency(time.Second)
+	<-done
+
+	if !ws.online() || ws.teamID() != "T012345" || ws.userID() != "U012345" || ws.currentLatency() != time.Second {
+		t.Errorf("workspace state was incorrect after connecting.")
+	}
+
+	ws.markDisconnected(now.Add(time.Minute))
+
+	if ws.online() {
+		t.Errorf("online() was incorrect after disconnecting, got: true, want false.")
+	}
+}
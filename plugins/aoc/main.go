@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/jyggen/pingu/pingu"
+	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 	"github.com/spf13/viper"
 )
@@ -20,25 +21,36 @@ type plugin struct {
 	client       *client
 	global       *leaderboard
 	leaderboards leaderboardList
+	refreshing   sync.Mutex
+}
+
+var Config = pingu.PluginConfig{
+	Defaults: map[string]interface{}{
+		"channel": "",
+		"owner":   0,
+		"session": "",
+		"timeout": 5,
+	},
 }
 
 var leaderboardRegex *regexp.Regexp
+var refreshRegex *regexp.Regexp
 var version string
-var lastRefresh time.Time
 
 func init() {
 	leaderboardRegex = regexp.MustCompile("^!leaderboard(?: ([\\d]{4}))?$")
+	refreshRegex = regexp.MustCompile("^!refresh$")
 }
 
 func New(c *viper.Viper) pingu.Plugin {
 	return pingu.Plugin(&plugin{
-		channel: c.GetString("aoc.channel"),
+		channel: c.GetString("channel"),
 		client: &client{
 			httpClient: &http.Client{
-				Timeout: c.GetDuration("aoc.timeout") * time.Second,
+				Timeout: c.GetDuration("timeout") * time.Second,
 			},
-			ownerId: c.GetInt("aoc.owner"),
-			session: c.GetString("aoc.session"),
+			ownerId: c.GetInt("owner"),
+			session: c.GetString("session"),
 		},
 		global: &leaderboard{},
 	})
@@ -54,27 +66,30 @@ func (pl *plugin) Author() pingu.Author {
 func (pl *plugin) Commands() pingu.Commands {
 	return pingu.Commands{
 		&pingu.Command{
+			Aliases:     []string{"lb"},
+			Channels:    []string{pl.channel},
+			Cooldown:    pingu.Cooldown{Channel: 30 * time.Second},
 			Description: "Prints either the global leaderboard, or the leaderboard for a specific year.",
 			Func:        pl.postLeaderboard,
+			Slash:       "/leaderboard",
 			Trigger:     leaderboardRegex,
 		},
-		&pingu.Command{
-			Description: "Forces a refresh of all leaderboards.",
-			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) {
-				if time.Now().Add(-time.Minute * 15).Before(lastRefresh) {
-					pi.Reply(ev, "Noot! Noot! The leaderboards were refreshed too recently!")
-					return
-				}
-
-				if ev.Channel != pl.channel {
-					pi.Reply(ev, fmt.Sprintf("Noot! Noot! That command is only available in <#%s>!", pl.channel))
-					return
-				}
+		pl.refreshCommand(),
+	}
+}
 
-				pl.refreshLeaderboards(pi)
-			},
-			Trigger: regexp.MustCompile("^!refresh$"),
+// refreshCommand is shared with the refresh tasks, which start its cooldown
+// whenever they've refreshed the leaderboards.
+func (pl *plugin) refreshCommand() *pingu.Command {
+	return &pingu.Command{
+		Audit:       true,
+		Channels:    []string{pl.channel},
+		Cooldown:    pingu.Cooldown{Global: 15 * time.Minute},
+		Description: "Forces a refresh of all leaderboards.",
+		Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) error {
+			return pl.refreshLeaderboards(pi)
 		},
+		Trigger: refreshRegex,
 	}
 }
 
@@ -85,16 +100,29 @@ func (pl *plugin) Name() string {
 func (pl *plugin) Tasks() pingu.Tasks {
 	return pingu.Tasks{
 		&pingu.Task{
-			Func: pl.refreshLeaderboards,
-			Spec: "*/15 * 1-25 DEC *",
+			CatchUp:       true,
+			Func:          pl.refreshLeaderboards,
+			Location:      time.UTC,
+			Name:          "refresh-december",
+			SkipIfRunning: true,
+			Spec:          "*/15 * 1-25 DEC *",
+			Timeout:       5 * time.Minute,
 		},
 		&pingu.Task{
-			Func: pl.refreshLeaderboards,
-			Spec: "0 * * JAN-NOV *",
+			CatchUp:       true,
+			Func:          pl.refreshLeaderboards,
+			Jitter:        5 * time.Minute,
+			Location:      time.UTC,
+			Name:          "refresh",
+			SkipIfRunning: true,
+			Spec:          "0 * * JAN-NOV *",
+			Timeout:       5 * time.Minute,
 		},
 		&pingu.Task{
-			Func: pl.announceNewDay,
-			Spec: "0 5 1-25 DEC *",
+			Func:     pl.announceNewDay,
+			Location: time.UTC,
+			Name:     "announce-day",
+			Spec:     "0 5 1-25 DEC *",
 		},
 	}
 }
@@ -149,14 +177,22 @@ Loop:
 	}
 }
 
-func (pl *plugin) announceNewDay(pi *pingu.Pingu) {
+func (pl *plugin) announceNewDay(pi *pingu.Pingu) error {
 	year, _, day := time.Now().Date()
 
-	pi.Say(fmt.Sprintf(
+	ref, err := pi.Say(fmt.Sprintf(
 		"Noot! Noot! <https://adventofcode.com/%[1]d/day/%[2]d|Day %[2]d of %[1]d is now available!> Please keep spoilers to a minimum and instead use a thread on this very message to discuss today's challenge. Happy coding!",
 		year,
 		day,
 	), pl.channel)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = pi.ReplyInThread(ref, fmt.Sprintf("Noot! Noot! Spoilers for day %d go here!", day))
+
+	return err
 }
 
 func (pl *plugin) announceChanges(pi *pingu.Pingu, a leaderboard, b leaderboard) {
@@ -248,7 +284,7 @@ func (pl *plugin) buildChangeMessage(l starList) string {
 	return starsString
 }
 
-func (pl *plugin) buildLeaderboard(l *leaderboard) string {
+func (pl *plugin) buildLeaderboard(l *leaderboard) pingu.RichMessage {
 	availableStars := calculateAvailableStars(time.Now(), l.Year)
 	message := ""
 
@@ -281,15 +317,26 @@ func (pl *plugin) buildLeaderboard(l *leaderboard) string {
 		)
 	}
 
-	return message
-}
+	title := "Global leaderboard"
+
+	if l.Year != 0 {
+		title = fmt.Sprintf("Leaderboard for %d", l.Year)
+	}
 
-func (pl *plugin) postLeaderboard(pi *pingu.Pingu, ev *slack.MessageEvent) {
-	if ev.Channel != pl.channel {
-		pi.Reply(ev, fmt.Sprintf("Noot! Noot! That command is only available in <#%s>!", pl.channel))
-		return
+	if message == "" {
+		message = "Noot! Noot! Nobody has collected any stars yet!"
 	}
 
+	return pingu.RichMessage{
+		Blocks: []pingu.Block{
+			pingu.Header{Text: title},
+			pingu.Section{Text: message},
+			pingu.Context{Elements: []string{fmt.Sprintf("%d stars available.", availableStars)}},
+		},
+	}
+}
+
+func (pl *plugin) postLeaderboard(pi *pingu.Pingu, ev *slack.MessageEvent) error {
 	var board *leaderboard
 
 	match := leaderboardRegex.FindStringSubmatch(ev.Text)
@@ -304,14 +351,15 @@ func (pl *plugin) postLeaderboard(pi *pingu.Pingu, ev *slack.MessageEvent) {
 		}
 
 		if board == nil {
-			pi.Reply(ev, fmt.Sprintf("Noot! Noot! %d does not have a leaderboard!", year))
-			return
+			return pingu.NewUserError("Noot! Noot! %d does not have a leaderboard!", year)
 		}
 	} else {
 		board = pl.global
 	}
 
-	pi.Say(pl.buildLeaderboard(board), ev.Channel)
+	_, err := pi.Respond(ev, pl.buildLeaderboard(board))
+
+	return err
 }
 
 func (pl *plugin) refreshGlobalLeaderboard() {
@@ -348,7 +396,12 @@ func (pl *plugin) refreshGlobalLeaderboard() {
 	pl.global.Sort()
 }
 
-func (pl *plugin) refreshLeaderboards(pi *pingu.Pingu) {
+// refreshLeaderboards is run both by tasks and by !refresh, so refreshes are
+// serialized to keep them from modifying the leaderboards at the same time.
+func (pl *plugin) refreshLeaderboards(pi *pingu.Pingu) error {
+	pl.refreshing.Lock()
+	defer pl.refreshing.Unlock()
+
 Loop:
 	for _, year := range getValidYears(time.Now()) {
 		for _, l := range pl.leaderboards {
@@ -362,6 +415,8 @@ Loop:
 		})
 	}
 
+	var failed error
+	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	wg.Add(len(pl.leaderboards))
@@ -373,7 +428,9 @@ Loop:
 			after := *l
 
 			if err != nil {
-				pi.Logger().Error(err)
+				mu.Lock()
+				failed = errors.WithMessage(err, fmt.Sprintf("unable to refresh the %d leaderboard", l.Year))
+				mu.Unlock()
 			} else if len(before.Members) != 0 {
 				pl.announceChanges(pi, before, after)
 			}
@@ -392,5 +449,7 @@ Loop:
 		pl.announceLeft(pi, before, after)
 	}
 
-	lastRefresh = time.Now()
+	pi.StartCooldown(pl.refreshCommand())
+
+	return failed
 }
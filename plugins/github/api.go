@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+type issueResponse struct {
+	Comments    int                  `json:"comments"`
+	HtmlUrl     string               `json:"html_url"`
+	Labels      []labelResponse      `json:"labels"`
+	Number      int                  `json:"number"`
+	PullRequest *pullRequestResponse `json:"pull_request"`
+	State       string               `json:"state"`
+	Title       string               `json:"title"`
+	User        userResponse         `json:"user"`
+}
+
+type labelResponse struct {
+	Name string `json:"name"`
+}
+
+type pullRequestResponse struct {
+	Merged   bool        `json:"merged"`
+	MergedAt interface{} `json:"merged_at"`
+}
+
+type userResponse struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type client struct {
+	baseUrl    string
+	httpClient *http.Client
+	token      string
+}
+
+func (c *client) GetIssue(repository string, number int) (issueResponse, error) {
+	var jsonData issueResponse
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%srepos/%s/issues/%d", c.baseUrl, repository, number), nil)
+
+	req.Header.Set("Accept", "application/json")
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return jsonData, errors.WithMessage(err, "http request failed")
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return jsonData, errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return jsonData, errors.WithMessage(err, "unable to read response body")
+	}
+
+	err = json.Unmarshal(body, &jsonData)
+
+	if err != nil {
+		return jsonData, errors.WithMessage(err, "unable to unmarshal json")
+	}
+
+	return jsonData, nil
+}
+
+func (i issueResponse) IsMerged() bool {
+	return i.PullRequest != nil && (i.PullRequest.Merged || i.PullRequest.MergedAt != nil)
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jyggen/pingu/pingu"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/spf13/viper"
+)
+
+type plugin struct {
+	branches     []string
+	client       *client
+	repositories map[string]string
+	secret       string
+}
+
+var Config = pingu.PluginConfig{
+	Defaults: map[string]interface{}{
+		"base_url":     "https://api.github.com/",
+		"branches":     []string{},
+		"repositories": []string{},
+		"secret":       "",
+		"timeout":      5,
+		"token":        "",
+	},
+}
+
+var referenceRegex *regexp.Regexp
+var version string
+
+func init() {
+	referenceRegex = regexp.MustCompile("(?:^|[^\\w/.-])([\\w.-]+/[\\w.-]+)#([\\d]+)\\b")
+}
+
+func New(c *viper.Viper) pingu.Plugin {
+	baseUrl := c.GetString("base_url")
+
+	if baseUrl == "" {
+		baseUrl = "https://api.github.com/"
+	}
+
+	if !strings.HasSuffix(baseUrl, "/") {
+		baseUrl += "/"
+	}
+
+	return pingu.Plugin(&plugin{
+		branches: c.GetStringSlice("branches"),
+		client: &client{
+			baseUrl: baseUrl,
+			httpClient: &http.Client{
+				Timeout: c.GetDuration("timeout") * time.Second,
+			},
+			token: c.GetString("token"),
+		},
+		repositories: parseRepositories(c.GetStringSlice("repositories")),
+		secret:       c.GetString("secret"),
+	})
+}
+
+func (pl *plugin) Author() pingu.Author {
+	return pingu.Author{
+		Email: "jonas@stendahl.me",
+		Name:  "Jonas Stendahl",
+	}
+}
+
+func (pl *plugin) Commands() pingu.Commands {
+	return pingu.Commands{
+		&pingu.Command{
+			Accept:      pingu.AcceptEdits,
+			Description: "Retrieves one or multiple issues or pull requests from GitHub.",
+			Func:        pl.postIssues,
+			Trigger:     referenceRegex,
+		},
+	}
+}
+
+func (pl *plugin) Name() string {
+	return "GitHub"
+}
+
+func (pl *plugin) Routes() pingu.Routes {
+	return pingu.Routes{
+		&pingu.Route{
+			Func:   pl.receiveWebhook,
+			Method: http.MethodPost,
+			Path:   "/github",
+		},
+	}
+}
+
+func (pl *plugin) Tasks() pingu.Tasks {
+	return pingu.Tasks{}
+}
+
+func (pl *plugin) Version() string {
+	return version
+}
+
+func (pl *plugin) channel(repository string) (string, bool) {
+	repository = strings.ToLower(repository)
+	owner := strings.SplitN(repository, "/", 2)[0]
+
+	for _, key := range []string{repository, owner + "/*", "*"} {
+		if ch, ok := pl.repositories[key]; ok {
+			return ch, true
+		}
+	}
+
+	return "", false
+}
+
+func (pl *plugin) postIssues(pi *pingu.Pingu, ev *slack.MessageEvent) error {
+	matches := referenceRegex.FindAllStringSubmatch(ev.Text, -1)
+
+	if matches == nil {
+		return nil
+	}
+
+	issues := make([]*issueResponse, len(matches))
+
+	var cause error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	wg.Add(len(matches))
+
+	for i, match := range matches {
+		go (func(i int, repository string, number string) {
+			defer wg.Done()
+
+			n, _ := strconv.Atoi(number)
+			issue, err := pl.client.GetIssue(repository, n)
+
+			if err != nil {
+				mu.Lock()
+				cause = err
+				mu.Unlock()
+				return
+			}
+
+			issues[i] = &issue
+		})(i, match[1], match[2])
+	}
+
+	wg.Wait()
+
+	blocks := make([]pingu.Block, 0, len(issues)*3)
+	missing := make([]string, 0, len(issues))
+
+	for i, match := range matches {
+		if issues[i] == nil {
+			missing = append(missing, match[1]+"#"+match[2])
+			continue
+		}
+
+		if len(blocks) > 0 {
+			blocks = append(blocks, pingu.Divider{})
+		}
+
+		blocks = append(blocks, createIssueBlocks(match[1], *issues[i])...)
+	}
+
+	if len(blocks) > 0 {
+		if _, err := pi.Send(ev.Channel, pingu.RichMessage{Blocks: blocks}); err != nil {
+			return err
+		}
+	}
+
+	numOfMissing := len(missing)
+
+	if numOfMissing > 0 {
+		errorMessage := strings.Join([]string{
+			strings.Join(missing[:numOfMissing-1], ", "),
+			missing[numOfMissing-1],
+		}, " and ")
+
+		if errorMessage[0:5] == " and " {
+			errorMessage = errorMessage[5:]
+		}
+
+		return pingu.WrapUserError(cause, "I was unable to retrieve %s.", errorMessage)
+	}
+
+	return nil
+}
+
+func (pl *plugin) receiveWebhook(pi *pingu.Pingu, w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 25<<20))
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !verifySignature(pl.secret, r.Header, body) {
+		pi.Logger().WithField("remote", r.RemoteAddr).Warn("Webhook signature verification failed")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	event := eventName(r.Header)
+
+	if event == "ping" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var payload webhookPayload
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	ch, ok := pl.channel(payload.Repository.FullName)
+
+	if !ok {
+		return
+	}
+
+	msg, ok := createEventMessage(event, payload, pl.branches)
+
+	if !ok {
+		return
+	}
+
+	pi.Logger().WithFields(logrus.Fields{
+		"event":      event,
+		"repository": payload.Repository.FullName,
+	}).Info("Webhook event posted")
+
+	pi.Send(ch, msg)
+}
+
+func createIssueBlocks(repository string, issue issueResponse) []pingu.Block {
+	kind := "issue"
+
+	if issue.PullRequest != nil {
+		kind = "pull request"
+	}
+
+	indicator := indicatorSuccess
+	state := "Open"
+
+	if issue.IsMerged() {
+		indicator = indicatorMerged
+		state = "Merged"
+	} else if issue.State == "closed" {
+		indicator = indicatorFailure
+		state = "Closed"
+	}
+
+	body := fmt.Sprintf("_Opened by_ *%s*", issue.User.Login)
+
+	numOfLabels := len(issue.Labels)
+
+	if numOfLabels > 0 {
+		labels := make([]string, numOfLabels)
+
+		for i, label := range issue.Labels {
+			labels[i] = fmt.Sprintf("`%s`", label.Name)
+		}
+
+		body += " _and labeled_ " + strings.Join(labels, " ")
+	}
+
+	body += "."
+
+	reference := fmt.Sprintf("%s#%d", repository, issue.Number)
+
+	return []pingu.Block{
+		pingu.Section{
+			Text: "*<" + issue.HtmlUrl + "|" + reference + ">*: " + issue.Title,
+		},
+		pingu.Context{
+			Elements: []string{indicator + " " + state + " " + kind, body},
+		},
+	}
+}
+
+func parseRepositories(mappings []string) map[string]string {
+	repositories := make(map[string]string, len(mappings))
+
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+
+		repositories[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+
+	return repositories
+}
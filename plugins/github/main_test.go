@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRepositories(t *testing.T) {
+	testCases := []struct {
+		mappings []string
+		expected map[string]string
+	}{
+		{[]string{}, map[string]string{}},
+		{[]string{"jyggen/Pingu=C012345"}, map[string]string{"jyggen/pingu": "C012345"}},
+		{[]string{"jyggen/*=C012345", "*=C678901"}, map[string]string{"jyggen/*": "C012345", "*": "C678901"}},
+		{[]string{"jyggen/pingu", "=C012345", "jyggen/pingu="}, map[string]string{}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run("", func(t *testing.T) {
+			t.Parallel()
+
+			if actual := parseRepositories(testCase.mappings); !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("parseRepositories() was incorrect, got: %v, want %v.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestReferenceRegex(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected [][]string
+	}{
+		{"see jyggen/pingu#12", [][]string{{" jyggen/pingu#12", "jyggen/pingu", "12"}}},
+		{"(go-gitea/gitea#1) and a/b.c#3", [][]string{{"(go-gitea/gitea#1", "go-gitea/gitea", "1"}, {" a/b.c#3", "a/b.c", "3"}}},
+		{"https://github.com/jyggen/pingu#readme", nil},
+		{"jyggen/pingu#abc", nil},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.text, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := referenceRegex.FindAllStringSubmatch(testCase.text, -1); !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("referenceRegex was incorrect, got: %q, want %q.", actual, testCase.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+
+	"github.com/jyggen/pingu/pingu"
+)
+
+const (
+	indicatorFailure = ":red_circle:"
+	indicatorMerged  = ":large_purple_circle:"
+	indicatorNeutral = ":large_blue_circle:"
+	indicatorSuccess = ":large_green_circle:"
+)
+
+type webhookPayload struct {
+	Action      string              `json:"action"`
+	CheckRun    *checkRunPayload    `json:"check_run"`
+	CheckSuite  *checkSuitePayload  `json:"check_suite"`
+	Commits     []commitPayload     `json:"commits"`
+	Compare     string              `json:"compare"`
+	CompareUrl  string              `json:"compare_url"`
+	Context     string              `json:"context"`
+	Description string              `json:"description"`
+	Forced      bool                `json:"forced"`
+	PullRequest *pullRequestPayload `json:"pull_request"`
+	Ref         string              `json:"ref"`
+	Release     *releasePayload     `json:"release"`
+	Repository  repositoryPayload   `json:"repository"`
+	Review      *reviewPayload      `json:"review"`
+	Sender      userResponse        `json:"sender"`
+	Sha         string              `json:"sha"`
+	State       string              `json:"state"`
+	TargetUrl   string              `json:"target_url"`
+}
+
+type checkRunPayload struct {
+	CheckSuite struct {
+		HeadBranch string `json:"head_branch"`
+	} `json:"check_suite"`
+	Conclusion string `json:"conclusion"`
+	HeadSha    string `json:"head_sha"`
+	HtmlUrl    string `json:"html_url"`
+	Name       string `json:"name"`
+}
+
+type checkSuitePayload struct {
+	App struct {
+		Name string `json:"name"`
+	} `json:"app"`
+	Conclusion string `json:"conclusion"`
+	HeadBranch string `json:"head_branch"`
+	HeadSha    string `json:"head_sha"`
+}
+
+type commitPayload struct {
+	Author struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"author"`
+	Id      string `json:"id"`
+	Message string `json:"message"`
+	Url     string `json:"url"`
+}
+
+type pullRequestPayload struct {
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	HtmlUrl string       `json:"html_url"`
+	Merged  bool         `json:"merged"`
+	Number  int          `json:"number"`
+	Title   string       `json:"title"`
+	User    userResponse `json:"user"`
+}
+
+type releasePayload struct {
+	Author     userResponse `json:"author"`
+	HtmlUrl    string       `json:"html_url"`
+	Name       string       `json:"name"`
+	Prerelease bool         `json:"prerelease"`
+	TagName    string       `json:"tag_name"`
+}
+
+type repositoryPayload struct {
+	DefaultBranch string `json:"default_branch"`
+	FullName      string `json:"full_name"`
+	HtmlUrl       string `json:"html_url"`
+}
+
+type reviewPayload struct {
+	HtmlUrl string `json:"html_url"`
+	State   string `json:"state"`
+	Type    string `json:"type"`
+}
+
+func eventName(header http.Header) string {
+	for _, key := range []string{"X-GitHub-Event", "X-Gitea-Event", "X-Gogs-Event"} {
+		if name := header.Get(key); name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func verifySignature(secret string, header http.Header, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+
+	signatures := []struct {
+		header string
+		prefix string
+		hash   func() hash.Hash
+	}{
+		{"X-Hub-Signature-256", "sha256=", sha256.New},
+		{"X-Gitea-Signature", "", sha256.New},
+		{"X-Gogs-Signature", "", sha256.New},
+		{"X-Hub-Signature", "sha1=", sha1.New},
+	}
+
+	for _, signature := range signatures {
+		value := header.Get(signature.header)
+
+		if value == "" || !strings.HasPrefix(value, signature.prefix) {
+			continue
+		}
+
+		expected, err := hex.DecodeString(value[len(signature.prefix):])
+
+		if err != nil {
+			return false
+		}
+
+		mac := hmac.New(signature.hash, []byte(secret))
+
+		mac.Write(body)
+
+		return hmac.Equal(mac.Sum(nil), expected)
+	}
+
+	return false
+}
+
+func createEventMessage(event string, payload webhookPayload, branches []string) (pingu.RichMessage, bool) {
+	switch event {
+	case "pull_request":
+		return createPullRequestMessage(payload)
+	case "pull_request_review":
+		if payload.Action != "submitted" || payload.Review == nil {
+			return pingu.RichMessage{}, false
+		}
+
+		return createReviewMessage(payload, payload.Review.State)
+	case "pull_request_review_approved", "pull_request_review_rejected", "pull_request_review_comment":
+		return createReviewMessage(payload, strings.TrimPrefix(event, "pull_request_review_"))
+	case "push":
+		return createPushMessage(payload, branches)
+	case "release":
+		return createReleaseMessage(payload)
+	case "check_run", "check_suite", "status":
+		return createCheckMessage(event, payload)
+	default:
+		return pingu.RichMessage{}, false
+	}
+}
+
+func createPullRequestMessage(payload webhookPayload) (pingu.RichMessage, bool) {
+	pr := payload.PullRequest
+
+	if pr == nil {
+		return pingu.RichMessage{}, false
+	}
+
+	var indicator string
+	var verb string
+
+	switch {
+	case payload.Action == "opened":
+		indicator, verb = indicatorSuccess, "opened"
+	case payload.Action == "reopened":
+		indicator, verb = indicatorSuccess, "reopened"
+	case payload.Action == "ready_for_review":
+		indicator, verb = indicatorSuccess, "marked as ready for review"
+	case payload.Action == "closed" && pr.Merged:
+		indicator, verb = indicatorMerged, "merged"
+	case payload.Action == "closed":
+		indicator, verb = indicatorFailure, "closed"
+	default:
+		return pingu.RichMessage{}, false
+	}
+
+	link := fmt.Sprintf("<%s|%s#%d>", pr.HtmlUrl, payload.Repository.FullName, pr.Number)
+
+	return createMessage(
+		fmt.Sprintf("%s %s pull request %s#%d: %s", payload.Sender.Login, verb, payload.Repository.FullName, pr.Number, pr.Title),
+		fmt.Sprintf("%s *%s*: %s", indicator, link, pr.Title),
+		fmt.Sprintf("_Pull request %s by_ *%s*, _merging_ `%s` _into_ `%s`.", verb, payload.Sender.Login, pr.Head.Ref, pr.Base.Ref),
+	), true
+}
+
+func createReviewMessage(payload webhookPayload, state string) (pingu.RichMessage, bool) {
+	pr := payload.PullRequest
+
+	if pr == nil {
+		return pingu.RichMessage{}, false
+	}
+
+	var indicator string
+	var verb string
+
+	switch strings.ToLower(state) {
+	case "approved":
+		indicator, verb = indicatorSuccess, "approved"
+	case "changes_requested", "rejected":
+		indicator, verb = indicatorFailure, "requested changes to"
+	case "commented", "comment":
+		indicator, verb = indicatorNeutral, "reviewed"
+	default:
+		return pingu.RichMessage{}, false
+	}
+
+	link := fmt.Sprintf("<%s|%s#%d>", pr.HtmlUrl, payload.Repository.FullName, pr.Number)
+
+	return createMessage(
+		fmt.Sprintf("%s %s %s#%d: %s", payload.Sender.Login, verb, payload.Repository.FullName, pr.Number, pr.Title),
+		fmt.Sprintf("%s *%s*: %s", indicator, link, pr.Title),
+		fmt.Sprintf("*%s* _%s this pull request._", payload.Sender.Login, verb),
+	), true
+}
+
+func createPushMessage(payload webhookPayload, branches []string) (pingu.RichMessage, bool) {
+	if !strings.HasPrefix(payload.Ref, "refs/heads/") || len(payload.Commits) == 0 {
+		return pingu.RichMessage{}, false
+	}
+
+	branch := strings.TrimPrefix(payload.Ref, "refs/heads/")
+
+	if !isProtectedBranch(branch, payload.Repository.DefaultBranch, branches) {
+		return pingu.RichMessage{}, false
+	}
+
+	compare := payload.Compare
+
+	if compare == "" {
+		compare = payload.CompareUrl
+	}
+
+	var commitsLabel string
+
+	if len(payload.Commits) != 1 {
+		commitsLabel = "commits"
+	} else {
+		commitsLabel = "commit"
+	}
+
+	lines := make([]string, 0, len(payload.Commits))
+
+	for i, c := range payload.Commits {
+		if i == 5 {
+			lines = append(lines, fmt.Sprintf("_and %d more..._", len(payload.Commits)-i))
+			break
+		}
+
+		sha := c.Id
+
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+
+		lines = append(lines, fmt.Sprintf("<%s|`%s`> %s", c.Url, sha, strings.SplitN(c.Message, "\n", 2)[0]))
+	}
+
+	indicator := indicatorNeutral
+	verb := "pushed"
+
+	if payload.Forced {
+		indicator = indicatorFailure
+		verb = "force-pushed"
+	}
+
+	return pingu.RichMessage{
+		Blocks: []pingu.Block{
+			pingu.Section{
+				Text: fmt.Sprintf("%s *%s* %s <%s|%d %s> to `%s:%s`", indicator, payload.Sender.Login, verb, compare, len(payload.Commits), commitsLabel, payload.Repository.FullName, branch),
+			},
+			pingu.Section{
+				Text: strings.Join(lines, "\n"),
+			},
+		},
+		Text: fmt.Sprintf("%s %s %d %s to %s:%s", payload.Sender.Login, verb, len(payload.Commits), commitsLabel, payload.Repository.FullName, branch),
+	}, true
+}
+
+func createReleaseMessage(payload webhookPayload) (pingu.RichMessage, bool) {
+	release := payload.Release
+
+	if release == nil || payload.Action != "published" {
+		return pingu.RichMessage{}, false
+	}
+
+	name := release.Name
+
+	if name == "" {
+		name = release.TagName
+	}
+
+	kind := "Release"
+
+	if release.Prerelease {
+		kind = "Pre-release"
+	}
+
+	return createMessage(
+		fmt.Sprintf("%s published %s %s of %s", payload.Sender.Login, strings.ToLower(kind), name, payload.Repository.FullName),
+		fmt.Sprintf("%s *<%s|%s %s>* of `%s` has been published!", indicatorSuccess, release.HtmlUrl, kind, name, payload.Repository.FullName),
+		fmt.Sprintf("_Published by_ *%s*, _tagged_ `%s`.", payload.Sender.Login, release.TagName),
+	), true
+}
+
+func createCheckMessage(event string, payload webhookPayload) (pingu.RichMessage, bool) {
+	var branch string
+	var name string
+	var sha string
+	var url string
+
+	switch event {
+	case "check_run":
+		if payload.CheckRun == nil || payload.Action != "completed" || !isFailure(payload.CheckRun.Conclusion) {
+			return pingu.RichMessage{}, false
+		}
+
+		branch = payload.CheckRun.CheckSuite.HeadBranch
+		name = payload.CheckRun.Name
+		sha = payload.CheckRun.HeadSha
+		url = payload.CheckRun.HtmlUrl
+	case "check_suite":
+		if payload.CheckSuite == nil || payload.Action != "completed" || !isFailure(payload.CheckSuite.Conclusion) {
+			return pingu.RichMessage{}, false
+		}
+
+		branch = payload.CheckSuite.HeadBranch
+		name = payload.CheckSuite.App.Name
+		sha = payload.CheckSuite.HeadSha
+		url = payload.Repository.HtmlUrl + "/commit/" + sha + "/checks"
+	case "status":
+		if !isFailure(payload.State) {
+			return pingu.RichMessage{}, false
+		}
+
+		name = payload.Context
+		sha = payload.Sha
+		url = payload.TargetUrl
+	}
+
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+
+	text := fmt.Sprintf("_Failing on commit_ `%s`", sha)
+
+	if branch != "" {
+		text += fmt.Sprintf(" _of_ `%s`", branch)
+	}
+
+	text += "."
+
+	if payload.Description != "" {
+		text += " " + payload.Description
+	}
+
+	return createMessage(
+		fmt.Sprintf("%s failed on %s@%s", name, payload.Repository.FullName, sha),
+		fmt.Sprintf("%s *<%s|%s>* failed on `%s`", indicatorFailure, url, name, payload.Repository.FullName),
+		text,
+	), true
+}
+
+func createMessage(fallback string, headline string, details string) pingu.RichMessage {
+	return pingu.RichMessage{
+		Blocks: []pingu.Block{
+			pingu.Section{Text: headline},
+			pingu.Context{Elements: []string{details}},
+		},
+		Text: fallback,
+	}
+}
+
+func isFailure(conclusion string) bool {
+	switch conclusion {
+	case "failure", "timed_out", "action_required", "error":
+		return true
+	default:
+		return false
+	}
+}
+
+func isProtectedBranch(branch string, defaultBranch string, branches []string) bool {
+	if branch == defaultBranch {
+		return true
+	}
+
+	for _, b := range branches {
+		if b == branch {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+
+	testCases := []struct {
+		name     string
+		secret   string
+		header   string
+		value    string
+		expected bool
+	}{
+		{"github sha256", "secret", "X-Hub-Signature-256", "sha256=b4d0fd3983e1d5612eaebe005a2092e7176a5e0e6a583899433148eb91c11b4e", true},
+		{"github sha1", "secret", "X-Hub-Signature", "sha1=31798790e579957302561486caa0b660ffc38518", true},
+		{"gitea", "secret", "X-Gitea-Signature", "b4d0fd3983e1d5612eaebe005a2092e7176a5e0e6a583899433148eb91c11b4e", true},
+		{"wrong secret", "terces", "X-Hub-Signature-256", "sha256=b4d0fd3983e1d5612eaebe005a2092e7176a5e0e6a583899433148eb91c11b4e", false},
+		{"missing prefix", "secret", "X-Hub-Signature-256", "b4d0fd3983e1d5612eaebe005a2092e7176a5e0e6a583899433148eb91c11b4e", false},
+		{"invalid hex", "secret", "X-Gitea-Signature", "not-a-signature", false},
+		{"no secret", "", "X-Hub-Signature-256", "sha256=b4d0fd3983e1d5612eaebe005a2092e7176a5e0e6a583899433148eb91c11b4e", false},
+		{"no header", "secret", "X-Unknown-Signature", "b4d0fd3983e1d5612eaebe005a2092e7176a5e0e6a583899433148eb91c11b4e", false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			header := http.Header{}
+			header.Set(testCase.header, testCase.value)
+
+			if actual := verifySignature(testCase.secret, header, body); testCase.expected != actual {
+				t.Errorf("verifySignature() was incorrect, got: %v, want %v.", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestIsProtectedBranch(t *testing.T) {
+	testCases := []struct {
+		branch        string
+		defaultBranch string
+		branches      []string
+		expected      bool
+	}{
+		{"master", "master", []string{}, true},
+		{"main", "master", []string{}, false},
+		{"release", "master", []string{"develop", "release"}, true},
+		{"feature", "master", []string{"develop", "release"}, false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.branch, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := isProtectedBranch(testCase.branch, testCase.defaultBranch, testCase.branches); testCase.expected != actual {
+				t.Errorf("isProtectedBranch() was incorrect, got: %v, want %v.", actual, testCase.expected)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/slack-go/slack"
 	"github.com/spf13/viper"
 	"regexp"
+	"strings"
 )
 
 type plugin struct{}
@@ -27,10 +28,13 @@ func (pl *plugin) Commands() pingu.Commands {
 	return pingu.Commands{
 		&pingu.Command{
 			Description: "Lists all available commands.",
-			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) {
-				pi.Reply(ev, generateHelpOutput(pi))
+			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) error {
+				_, err := pi.Reply(ev, generateHelpOutput(pi))
+
+				return err
 			},
-			Trigger: regexp.MustCompile("^!help$"),
+			Response: pingu.ResponseEphemeral,
+			Trigger:  regexp.MustCompile("^!help$"),
 		},
 	}
 }
@@ -67,10 +71,30 @@ func generateHelpOutput(pi *pingu.Pingu) string {
 			version = "ver. " + version
 		}
 
-		output += fmt.Sprintf("%s (%s):\n", pl.Name(), version)
+		name := pl.Name()
+		prefix := "!"
+
+		if instance := pi.Instance(pl); instance != "" {
+			name += " [" + instance + "]"
+		}
+
+		if namespace := pi.Namespace(pl); namespace != "" {
+			prefix = "!" + namespace + "."
+		}
+
+		output += fmt.Sprintf("%s (%s):\n", name, version)
 
 		for _, cmd := range pl.Commands() {
-			trigger := cmd.Trigger.String()
+			trigger := strings.Replace(cmd.Trigger.String(), "!", prefix, 1)
+
+			if cmd.Slash != "" {
+				trigger += " (" + pi.Slash(pl, cmd) + ")"
+			}
+
+			for _, alias := range cmd.Aliases {
+				trigger += " (" + prefix + alias + ")"
+			}
+
 			output += fmt.Sprintf("%s: %s\n", trigger, cmd.Description)
 		}
 
@@ -17,6 +17,15 @@ type plugin struct {
 	baseUrl string
 }
 
+var Config = pingu.PluginConfig{
+	Defaults: map[string]interface{}{
+		"base_url": "",
+		"password": "",
+		"timeout":  5,
+		"username": "",
+	},
+}
+
 var commandRegex *regexp.Regexp
 var version string
 
@@ -26,17 +35,17 @@ func init() {
 
 func New(c *viper.Viper) pingu.Plugin {
 	transport := jira.BasicAuthTransport{
-		Username: c.GetString("jira.username"),
-		Password: c.GetString("jira.password"),
+		Username: c.GetString("username"),
+		Password: c.GetString("password"),
 	}
 
 	httpClient := transport.Client()
-	httpClient.Timeout = c.GetDuration("jira.timeout") * time.Second
-	client, _ := jira.NewClient(httpClient, c.GetString("jira.base_url"))
+	httpClient.Timeout = c.GetDuration("timeout") * time.Second
+	client, _ := jira.NewClient(httpClient, c.GetString("base_url"))
 
 	return pingu.Plugin(&plugin{
 		Client:  client,
-		baseUrl: c.GetString("jira.base_url"),
+		baseUrl: c.GetString("base_url"),
 	})
 }
 
@@ -50,6 +59,7 @@ func (pl *plugin) Author() pingu.Author {
 func (pl *plugin) Commands() pingu.Commands {
 	return pingu.Commands{
 		&pingu.Command{
+			Accept:      pingu.AcceptEdits,
 			Description: "Retrieves one or multiple issues from JIRA.",
 			Func:        pl.postJiraIssue,
 			Trigger:     commandRegex,
@@ -61,11 +71,11 @@ func (pl *plugin) Name() string {
 	return "Jira"
 }
 
-func (pl *plugin) postJiraIssue(pi *pingu.Pingu, ev *slack.MessageEvent) {
+func (pl *plugin) postJiraIssue(pi *pingu.Pingu, ev *slack.MessageEvent) error {
 	matches := commandRegex.FindAllStringSubmatch(ev.Text, -1)
 
 	if matches == nil {
-		return
+		return nil
 	}
 
 	issues := make([]string, len(matches))
@@ -75,32 +85,52 @@ func (pl *plugin) postJiraIssue(pi *pingu.Pingu, ev *slack.MessageEvent) {
 	}
 
 	invalid := make([]string, 0)
-	attachments := make([]slack.Attachment, 0)
+	found := make([]*jira.Issue, len(issues))
 
+	var cause error
+	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	wg.Add(len(issues))
 
-	for _, issueId := range issues {
-		go (func(issueId string) {
+	for i, issueId := range issues {
+		go (func(i int, issueId string) {
+			defer wg.Done()
+
 			issue, _, err := pl.Issue.Get(issueId, nil)
 
 			if err != nil {
+				mu.Lock()
+				cause = err
 				invalid = append(invalid, issueId)
-				pi.Logger().Error(err)
-				wg.Done()
+				mu.Unlock()
 				return
 			}
 
-			attachments = append(attachments, createIssueAttachment(issue, pl.baseUrl))
-			wg.Done()
-		})(issueId)
+			found[i] = issue
+		})(i, issueId)
 	}
 
 	wg.Wait()
 
-	if len(attachments) > 0 {
-		pi.SendAttachments(attachments, "", ev.Channel)
+	blocks := make([]pingu.Block, 0)
+
+	for _, issue := range found {
+		if issue == nil {
+			continue
+		}
+
+		if len(blocks) > 0 {
+			blocks = append(blocks, pingu.Divider{})
+		}
+
+		blocks = append(blocks, createIssueBlocks(issue, pl.baseUrl)...)
+	}
+
+	if len(blocks) > 0 {
+		if _, err := pi.Send(ev.Channel, pingu.RichMessage{Blocks: blocks}); err != nil {
+			return err
+		}
 	}
 
 	numOfInvalid := len(invalid)
@@ -115,8 +145,10 @@ func (pl *plugin) postJiraIssue(pi *pingu.Pingu, ev *slack.MessageEvent) {
 			errorMessage = errorMessage[5:]
 		}
 
-		pi.Reply(ev, fmt.Sprintf("I was unable to retrieve %s.", errorMessage))
+		return pingu.WrapUserError(cause, "I was unable to retrieve %s.", errorMessage)
 	}
+
+	return nil
 }
 
 func (pl *plugin) Tasks() pingu.Tasks {
@@ -127,7 +159,7 @@ func (pl *plugin) Version() string {
 	return version
 }
 
-func createIssueAttachment(issue *jira.Issue, baseUrl string) slack.Attachment {
+func createIssueBlocks(issue *jira.Issue, baseUrl string) []pingu.Block {
 	body := ""
 
 	if issue.Fields.Assignee != nil {
@@ -166,27 +198,31 @@ func createIssueAttachment(issue *jira.Issue, baseUrl string) slack.Attachment {
 	}
 
 	link := "<" + baseUrl + "browse/" + issue.Key + "|" + issue.Key + ">"
+	context := []string{getIssueIndicator(issue.Fields.Status) + " " + issue.Fields.Status.Name + " " + issue.Fields.Type.Name}
+
+	if body != "" {
+		context = append(context, body)
+	}
 
-	return slack.Attachment{
-		AuthorIcon: issue.Fields.Status.IconURL,
-		AuthorName: issue.Fields.Status.Name + " " + issue.Fields.Type.Name,
-		Color:      getIssueColor(issue.Fields.Status),
-		Fallback:   issue.Key + ": " + issue.Fields.Summary,
-		Pretext:    "*" + link + "*: " + issue.Fields.Summary,
-		MarkdownIn: []string{"pretext", "text"},
-		Text:       body,
+	return []pingu.Block{
+		pingu.Section{
+			Text: "*" + link + "*: " + issue.Fields.Summary,
+		},
+		pingu.Context{
+			Elements: context,
+		},
 	}
 }
 
-func getIssueColor(status *jira.Status) string {
+func getIssueIndicator(status *jira.Status) string {
 	switch status.StatusCategory.ColorName {
 	case "green":
-		return "#14892C"
+		return ":large_green_circle:"
 	case "yellow":
-		return "#F6C342"
+		return ":large_yellow_circle:"
 	case "blue-gray":
-		return "#4A6785"
+		return ":large_blue_circle:"
 	default:
-		return "#4A6785"
+		return ":large_blue_circle:"
 	}
 }
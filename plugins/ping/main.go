@@ -28,8 +28,10 @@ func (pl *plugin) Commands() pingu.Commands {
 	return pingu.Commands{
 		&pingu.Command{
 			Description: "Reports my current latency towards Slack.",
-			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) {
-				pi.Reply(ev, fmt.Sprintf("My current latency towards Slack is %s.", durafmt.ParseShort(pi.Latency())))
+			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) error {
+				_, err := pi.Reply(ev, fmt.Sprintf("My current latency towards Slack is %s.", durafmt.ParseShort(pi.Latency())))
+
+				return err
 			},
 			Trigger: regexp.MustCompile("^!ping$"),
 		},
@@ -29,12 +29,23 @@ func (pl *plugin) Commands() pingu.Commands {
 	return pingu.Commands{
 		&pingu.Command{
 			Description: "Reports my current uptime.",
-			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) {
-				pi.Reply(ev, fmt.Sprintf(
-					"My current uptime is %s, and I've been connected for %s.",
-					durafmt.ParseShort(time.Since(pi.StartedAt())),
-					durafmt.ParseShort(time.Since(pi.ConnectedAt())),
+			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) error {
+				now := time.Now()
+				day := pi.Connectivity(now.Add(-24 * time.Hour))
+				week := pi.Connectivity(now.Add(-7 * 24 * time.Hour))
+
+				_, err := pi.Reply(ev, fmt.Sprintf(
+					"My current uptime is %s, and I've been connected for %s.\n"+
+						"In the last day I've reconnected %s and been down for %s, and in the last week %s and %s.",
+					durafmt.ParseShort(now.Sub(pi.StartedAt())),
+					durafmt.ParseShort(now.Sub(pi.ConnectedAt())),
+					times(day.Reconnects),
+					durafmt.ParseShort(day.Downtime),
+					times(week.Reconnects),
+					durafmt.ParseShort(week.Downtime),
 				))
+
+				return err
 			},
 			Trigger: regexp.MustCompile("^!uptime$"),
 		},
@@ -52,3 +63,11 @@ func (pl *plugin) Tasks() pingu.Tasks {
 func (pl *plugin) Version() string {
 	return version
 }
+
+func times(n int) string {
+	if n == 1 {
+		return "once"
+	}
+
+	return fmt.Sprintf("%d times", n)
+}
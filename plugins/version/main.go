@@ -26,9 +26,12 @@ func (pl *plugin) Author() pingu.Author {
 func (pl *plugin) Commands() pingu.Commands {
 	return pingu.Commands{
 		&pingu.Command{
+			Aliases:     []string{"v"},
 			Description: "Reports the version of myself I'm currently running.",
-			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) {
-				pi.Reply(ev, fmt.Sprintf("I'm currently running Pingu %s, built at %s.", pi.FriendlyVersion(), pi.BuiltAt()))
+			Func: func(pi *pingu.Pingu, ev *slack.MessageEvent) error {
+				_, err := pi.Reply(ev, fmt.Sprintf("I'm currently running Pingu %s, built at %s.", pi.FriendlyVersion(), pi.BuiltAt()))
+
+				return err
 			},
 			Trigger: regexp.MustCompile("^!version$"),
 		},